@@ -8,6 +8,8 @@ import (
 	"go/types"
 	"io"
 	"log"
+	"os"
+	"strconv"
 	"sync"
 
 	"github.com/pkg/errors"
@@ -22,23 +24,93 @@ import (
 type Analyzer struct {
 	logger              *log.Logger
 	processedGoroutines sync.Map
-	target              Target
+	targets             Targets
+	// reporters receive recovered values in deferred closures that call
+	// recover() themselves instead of deferring a handler.
+	reporters Targets
+	labels    labelPolicy
+	// prologue lists functions whose calls and defers may precede the
+	// handler defer.
+	prologue Targets
+	// flow replaces the first statement rule with a control flow check on
+	// the SSA form of local goroutine bodies.
+	flow bool
+	// steps are calls every goroutine must make, in order, in its opening
+	// statements.
+	steps steps
+	// deferOrder selects how defers registered before the handler are
+	// treated; deferAllow lists those the allowlist policy accepts.
+	deferOrder deferOrder
+	deferAllow Targets
+	// deadGuards reports handler defers in functions that are never started
+	// as goroutines or are called from guarded code.
+	deadGuards bool
+	// bypasses terminate without a panic, so calling them from a guarded
+	// goroutine leaves the handler nothing to report.
+	bypasses Targets
+	// exemptPanicFree accepts goroutines without a handler when their body is
+	// proven unable to panic.
+	exemptPanicFree bool
+	// nonDeferred reports calls to the handler anywhere in the package that
+	// are not deferred and so recover nothing.
+	nonDeferred bool
+	// verifyHandlers checks that the configured handlers call recover()
+	// directly and use the recovered value.
+	verifyHandlers bool
+	// ssaFunctions holds, per pass, the SSA functions of the package by body.
+	ssaFunctions sync.Map
+	// loadedTargetPackages caches target packages that had to be loaded
+	// because they were not in the import graph.
+	loadedTargetPackages sync.Map
+	// reportedDiagnostics deduplicates findings inside function bodies.
+	reportedDiagnostics sync.Map
+	// checkedParams records the parameters started as goroutines whose call
+	// site arguments have been checked.
+	checkedParams sync.Map
+	// resolvingFields holds the func-valued fields whose values are being
+	// checked, so that fields assigned to each other are not followed forever.
+	resolvingFields sync.Map
 }
 
 func New(logger *log.Logger) *analysis.Analyzer {
 	goroutinedeferguard := newAnalyzer(logger)
 
 	analyzer := &analysis.Analyzer{
-		Name:     "goroutinedeferguard",
-		Doc:      fmt.Sprintf("reports missing defer call to defined function as first actoin in goroutines"),
-		Requires: []*analysis.Analyzer{inspect.Analyzer},
+		Name:      "goroutinedeferguard",
+		Doc:       fmt.Sprintf("reports missing defer call to defined function as first actoin in goroutines"),
+		Requires:  []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes: []analysis.Fact{new(handlerFact), new(labelsFact), new(guardsFact)},
 		Run: func(pass *analysis.Pass) (interface{}, error) {
 			return goroutinedeferguard.Run(pass)
 		},
 	}
 
 	analyzer.Flags.Init(analyzer.Name, flag.ExitOnError)
-	analyzer.Flags.Var(&goroutinedeferguard.target, "target", "fully qualified handler identifier in the form full/pkg/path.Foo")
+	analyzer.Flags.Var(&targetsFlag{targets: &goroutinedeferguard.targets}, "target", "fully qualified handler identifier in the form full/pkg/path.Foo; repeat or comma-separate to accept several handlers")
+	analyzer.Flags.Var(&goroutinedeferguard.reporters, "reporter", "fully qualified function that deferred closures calling recover() themselves must pass the recovered value to; repeat or comma-separate for several")
+	analyzer.Flags.Var(&goroutinedeferguard.prologue, "prologue", "fully qualified function whose calls or defers may come before the handler defer, such as (*sync.WaitGroup).Done; repeat or comma-separate for several")
+	analyzer.Flags.Var(&goroutinedeferguard.steps, "step", "fully qualified function every goroutine must call in its opening statements, prefixed with defer: when it must be deferred; repeat or comma-separate for an ordered list")
+	analyzer.Flags.Var(&goroutinedeferguard.deferOrder, "defer-order", "report defers registered before the handler, which run after it: allowlist accepts the -defer-allow functions, strict none")
+	analyzer.Flags.Var(&goroutinedeferguard.deferAllow, "defer-allow", "fully qualified function that may be deferred before the handler under -defer-order=allowlist; repeat or comma-separate for several")
+	analyzer.Flags.BoolVar(&goroutinedeferguard.deadGuards, "dead-guards", false, "report handler defers in functions never started as goroutines, across the program for exported ones, and in functions called from guarded code")
+	analyzer.Flags.Var(&goroutinedeferguard.bypasses, "bypass", "fully qualified function that terminates without a panic and must not be called from guarded goroutines, such as os.Exit, log.Fatal*, log.Logger.Fatal* or runtime.Goexit; repeat or comma-separate for several")
+	analyzer.Flags.BoolVar(&goroutinedeferguard.flow, "ssa", false, "instead of requiring the handler defer first, check on the SSA form that it is registered before any operation that can panic")
+	analyzer.Flags.BoolVar(&goroutinedeferguard.exemptPanicFree, "exempt-panic-free", false, "accept goroutines without a handler when their body provably cannot panic; the reasoning is logged")
+	analyzer.Flags.BoolVar(&goroutinedeferguard.nonDeferred, "non-deferred", false, "report calls to the handler that are not deferred, which recover nothing, with a fix that defers them")
+	analyzer.Flags.BoolVar(&goroutinedeferguard.verifyHandlers, "verify-handlers", false, "report configured handlers that never call recover(), call it only from a nested function or discard the recovered value")
+	analyzer.Flags.BoolFunc("verbose", "log analysis details, such as why goroutines were exempted, to stderr", func(value string) error {
+		verbose, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		if verbose {
+			goroutinedeferguard.logger = log.New(os.Stderr, "", log.LstdFlags)
+		}
+		return nil
+	})
+	analyzer.Flags.IntVar(&goroutinedeferguard.labels.arg, "label-arg", -1, "index of the handler argument carrying the goroutine label, which must then be a constant string; -1 disables label checks")
+	analyzer.Flags.Var(&goroutinedeferguard.labels.pattern, "label-pattern", "regular expression goroutine labels must match")
+	analyzer.Flags.Var(&goroutinedeferguard.labels.unique, "label-unique", "scope in which goroutine labels must be unique: package or module")
 
 	return analyzer
 }
@@ -50,10 +122,11 @@ func newAnalyzer(logger *log.Logger) *Analyzer {
 	return &Analyzer{
 		logger:              logger,
 		processedGoroutines: sync.Map{},
-		target: Target{
+		targets: Targets{{
 			PackagePath: "",
 			FuncName:    DefaultTarget,
-		},
+		}},
+		labels: labelPolicy{arg: -1},
 	}
 }
 
@@ -69,10 +142,21 @@ func (p *Analyzer) Run(pass *analysis.Pass) (interface{}, error) {
 	}
 
 	// Inspect go statements
+	var firstGo token.Pos
 	inspected.Preorder(nodeFilter, func(n ast.Node) {
+		if !firstGo.IsValid() {
+			firstGo = n.Pos()
+		}
 		p.ProcessNode(pass, n)
 	})
 
+	p.verifyTargets(pass, firstGo)
+
+	p.reportDuplicateLabels(pass)
+	p.reportDeadGuards(pass, inspected)
+	p.reportNonDeferredHandlers(pass, inspected)
+	p.ssaFunctions.Delete(pass)
+
 	return nil, nil
 }
 
@@ -93,7 +177,11 @@ func (p *Analyzer) ProcessNode(pass *analysis.Pass, n ast.Node) {
 	case *ast.FuncLit: // anonymous function
 		pos := pass.Fset.Position(fun.Pos())
 		p.logger.Printf("found anonymous goroutine uri=%s column=%d", utils.URI(pos.Filename, pos.Line), pos.Column)
-		if err := p.checkGoroutine(fun.Body, pass.TypesInfo); err != nil {
+		err := p.checkGoroutine(pass, fun.Body, pass.TypesInfo)
+		if call := trampolineCall(pass, fun.Body); err != nil && call != nil {
+			err = p.checkTrampoline(pass, call, goStmt.Pos())
+		}
+		if err != nil {
 			p.logLinterError(pass, fun.Pos(), fun.Pos(), err)
 		}
 
@@ -113,12 +201,67 @@ func (p *Analyzer) ProcessNode(pass *analysis.Pass, n ast.Node) {
 			p.logLinterError(pass, goStmt.Pos(), goStmt.Pos(), err)
 		}
 
+	case *ast.IndexExpr, *ast.IndexListExpr: // instantiated generic function or method
+		pos := pass.Fset.Position(fun.Pos())
+		p.logger.Printf("found generic function call as goroutine functionName=%s uri=%s column=%d", types.ExprString(fun), utils.URI(pos.Filename, pos.Line), pos.Column)
+
+		if err := p.checkGoroutineDefinition(pass, fun, goStmt.Pos()); err != nil {
+			p.logLinterError(pass, goStmt.Pos(), goStmt.Pos(), err)
+		}
+
 	default:
 		p.logger.Printf("unexpected goroutine type type=%T", fun)
 	}
 }
 
-func (p *Analyzer) checkGoroutine(body *ast.BlockStmt, typeInfo *types.Info) error {
+// checkGoroutine verifies a goroutine body. typeInfo belongs to the package the
+// body was taken from, which is pass.Pkg unless the body had to be loaded from
+// another package.
+func (p *Analyzer) checkGoroutine(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) error {
+	var errs linterErrors
+	if err := p.checkGuard(pass, body, typeInfo); err != nil {
+		if !p.exempt(pass, body, typeInfo) {
+			errs = append(errs, err)
+		}
+	} else {
+		errs = append(errs, p.checkBypasses(pass, body, typeInfo)...)
+		errs = append(errs, p.checkCompetingRecovers(pass, body, typeInfo)...)
+	}
+	errs = append(errs, p.checkSteps(pass, body, typeInfo)...)
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
+}
+
+// exempt reports whether a goroutine that fails the guard check is accepted
+// because its body cannot panic.
+func (p *Analyzer) exempt(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) bool {
+	if !p.exemptPanicFree {
+		return false
+	}
+
+	uri := "external"
+	if typeInfo == pass.TypesInfo {
+		position := pass.Fset.Position(body.Pos())
+		uri = utils.URI(position.Filename, position.Line)
+	}
+	explanation, proofErr := p.provePanicFree(pass, body, typeInfo)
+	if proofErr != nil {
+		p.logger.Printf("goroutine not exempted uri=%s reason=%s", uri, proofErr.Error())
+		return false
+	}
+	p.logger.Printf("goroutine exempted because it cannot panic uri=%s reason=%s", uri, explanation)
+	return true
+}
+
+// checkGuard verifies that a goroutine body registers the handler in time.
+func (p *Analyzer) checkGuard(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) error {
 	if body == nil {
 		p.logger.Printf("missing function body")
 		return nil
@@ -129,213 +272,311 @@ func (p *Analyzer) checkGoroutine(body *ast.BlockStmt, typeInfo *types.Info) err
 		return nil
 	}
 
-	deferStatement, ok := body.List[0].(*ast.DeferStmt)
+	if p.flow {
+		if fn := p.ssaFunction(pass, body, typeInfo); fn != nil {
+			return p.checkGoroutineFlow(pass, fn, body, typeInfo)
+		}
+	}
+
+	first, err := p.skipPrologue(pass, body, typeInfo)
+	if err != nil {
+		return err
+	}
+	if first == len(body.List) {
+		return errors.New("no defer after the allowed prologue")
+	}
+
+	deferStatement, ok := body.List[first].(*ast.DeferStmt)
 	if !ok {
+		if first > 0 {
+			return errors.New("first statement after the allowed prologue is not defer")
+		}
 		return errors.New("first statement is not defer")
 	}
+	if p.deferOrder != "" {
+		deferStatement = p.leadingHandlerDefer(pass, body.List[first:], typeInfo)
+	}
+
+	if err := p.checkHandlerDefer(pass, deferStatement, typeInfo); err != nil {
+		return err
+	}
 
+	p.checkLabel(pass, deferStatement, typeInfo)
+	return p.checkDeferOrder(pass, body, deferStatement, typeInfo)
+}
+
+// checkHandlerDefer verifies that deferStatement registers a handler: a
+// target, or with reporters configured an inline recover() closure.
+func (p *Analyzer) checkHandlerDefer(pass *analysis.Pass, deferStatement *ast.DeferStmt, typeInfo *types.Info) error {
 	if err := p.matchTargetCall(deferStatement.Call.Fun, typeInfo); err != nil {
+		if trap := p.checkDeferredWrapper(pass, deferStatement, typeInfo); trap != nil {
+			return trap
+		}
+		if lit, ok := deferStatement.Call.Fun.(*ast.FuncLit); ok && len(p.reporters) > 0 {
+			if err := p.checkInlineRecover(lit, typeInfo); err != nil {
+				return errors.Wrap(err, "inline recover")
+			}
+			return nil
+		}
 		return errors.Wrap(err, "target mismatch")
 	}
-
 	return nil
 }
 
 func (p *Analyzer) matchTargetCall(call ast.Expr, typeInfo *types.Info) error {
-	switch expr := call.(type) {
+	return p.matchCall(p.targets, call, typeInfo)
+}
+
+// matchCall checks the function called by call against targets.
+func (p *Analyzer) matchCall(targets Targets, call ast.Expr, typeInfo *types.Info) error {
+	factory := false
+	if factoryCall, ok := call.(*ast.CallExpr); ok {
+		// defer factory(...)(): the deferred function is the one the factory returns
+		factory = true
+		call = factoryCall.Fun
+	}
+
+	switch expr := unindex(call).(type) {
 	case *ast.SelectorExpr:
-		return p.matchTargetSelector(expr, typeInfo)
+		return p.matchTargetSelector(targets, expr, factory, typeInfo)
 	case *ast.Ident:
-		return p.matchTargetIdent(expr, typeInfo)
+		return p.matchTargetIdent(targets, expr, factory, typeInfo)
 	default:
-		return errors.New("statement is not a selector or identifier")
+		return errors.New("statement is not a selector, identifier or handler factory call")
 	}
 }
 
-func (p *Analyzer) matchTargetIdent(ident *ast.Ident, typeInfo *types.Info) error {
+func (p *Analyzer) matchTargetIdent(targets Targets, ident *ast.Ident, factory bool, typeInfo *types.Info) error {
 	if typeInfo != nil {
 		if obj, ok := typeInfo.Uses[ident]; ok {
 			if fn, ok := obj.(*types.Func); ok {
-				return p.matchFuncObject(fn)
+				return p.matchFuncObject(targets, fn, factory)
 			}
 		}
 	}
 
-	if ident.Name != p.target.FuncName {
-		return errors.Errorf("expected call '%s', got '%s'", p.target.FuncName, ident.Name)
-	}
-	if p.target.PackagePath != "" {
-		return errors.Errorf("expected package '%s', got local identifier", p.target.PackagePath)
-	}
-	return nil
+	return targets.matchName(ident.Name, "local identifier", factory)
 }
 
-func (p *Analyzer) matchTargetSelector(selectorExpr *ast.SelectorExpr, typeInfo *types.Info) error {
+func (p *Analyzer) matchTargetSelector(targets Targets, selectorExpr *ast.SelectorExpr, factory bool, typeInfo *types.Info) error {
 	if typeInfo != nil {
 		if obj, ok := typeInfo.Uses[selectorExpr.Sel]; ok {
 			if fn, ok := obj.(*types.Func); ok {
-				return p.matchFuncObject(fn)
+				return p.matchFuncObject(targets, fn, factory)
 			}
 		}
 	}
 
-	if selectorExpr.Sel.Name != p.target.FuncName {
-		return errors.Errorf("expected call '%s', got '%s'", p.target.FuncName, selectorExpr.Sel.Name)
+	return targets.matchName(selectorExpr.Sel.Name, "unresolved selector", factory)
+}
+
+func (p *Analyzer) matchFuncObject(targets Targets, fn *types.Func, factory bool) error {
+	return targets.matchFunc(fn, factory, p.resolveTargetType)
+}
+
+// resolveTargetType finds the receiver type of a method target, first in the
+// import graph of from and then by loading the target package.
+func (p *Analyzer) resolveTargetType(t Target, from *types.Package) types.Type {
+	var pkg *types.Package
+	if from != nil {
+		pkg = findImportedPackage(from, t.PackagePath)
 	}
-	if p.target.PackagePath != "" {
-		return errors.Errorf("expected package '%s', got unresolved selector", p.target.PackagePath)
+	if pkg == nil && t.PackagePath != "" {
+		pkg, _ = p.loadTargetPackage(t.PackagePath)
+	}
+	if pkg == nil {
+		return nil
+	}
+
+	if obj, ok := pkg.Scope().Lookup(t.TypeName).(*types.TypeName); ok {
+		return obj.Type()
 	}
 	return nil
 }
 
-func (p *Analyzer) matchFuncObject(fn *types.Func) error {
-	if fn.Name() != p.target.FuncName {
-		return errors.Errorf("expected call '%s', got '%s'", p.target.FuncName, fn.Name())
+type loadedPackage struct {
+	pkg *types.Package
+	err error
+}
+
+// loadTargetPackage type-checks a target package outside the import graph.
+// Results are cached for the whole run.
+func (p *Analyzer) loadTargetPackage(path string) (*types.Package, error) {
+	if cached, ok := p.loadedTargetPackages.Load(path); ok {
+		loaded := cached.(loadedPackage)
+		return loaded.pkg, loaded.err
+	}
+
+	var loaded loadedPackage
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedTypes}
+	pkgs, err := packages.Load(cfg, path)
+	switch {
+	case err != nil:
+		loaded.err = errors.Wrap(err, "packages.Load failed")
+	case len(pkgs) == 0 || len(pkgs[0].Errors) > 0 || pkgs[0].Types == nil:
+		loaded.err = errors.Errorf("package %s not found", path)
+	default:
+		loaded.pkg = pkgs[0].Types
 	}
-	if p.target.PackagePath == "" {
-		return nil
+	if loaded.err != nil {
+		p.logger.Printf("cannot load target package pkg=%s reason=%s", path, loaded.err.Error())
 	}
 
-	pkgPath := ""
-	if fn.Pkg() != nil {
-		pkgPath = fn.Pkg().Path()
+	p.loadedTargetPackages.Store(path, loaded)
+	return loaded.pkg, loaded.err
+}
+
+// findImportedPackage returns the package with the given path among from and
+// its transitive imports. An empty path refers to from itself.
+func findImportedPackage(from *types.Package, path string) *types.Package {
+	if path == "" || from.Path() == path {
+		return from
 	}
-	if pkgPath == p.target.PackagePath {
-		return nil
+
+	seen := map[*types.Package]bool{}
+	queue := []*types.Package{from}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg.Path() == path {
+			return pkg
+		}
+		for _, imp := range pkg.Imports() {
+			if !seen[imp] {
+				seen[imp] = true
+				queue = append(queue, imp)
+			}
+		}
 	}
-	return errors.Errorf("expected package '%s', got '%s'", p.target.PackagePath, pkgPath)
+	return nil
 }
 
 func (p *Analyzer) checkGoroutineDefinition(pass *analysis.Pass, fun ast.Expr, callPos token.Pos) error {
-	var funcName string
-	var receiverType types.Type
+	var fn *types.Func
 
-	// Extract function name and receiver type if it's a method
-	switch e := fun.(type) {
+	// Resolve the called function or method to its object
+	switch e := unindex(fun).(type) {
 	case *ast.Ident:
-		funcName = e.Name
 		// Check if this identifier refers to a variable holding a function literal
-		obj := pass.TypesInfo.ObjectOf(e)
-		if obj == nil {
-			break
-		}
-
-		varObj, ok := obj.(*types.Var)
-		if !ok {
-			break
-		}
-
-		// This is a variable, try to find all its function literal assignments
-		funcLits := p.findAllFunctionLiteralAssignments(pass, varObj)
-		if len(funcLits) == 0 {
-			break
-		}
+		switch obj := pass.TypesInfo.ObjectOf(e).(type) {
+		case *types.Func:
+			fn = obj
+		case *types.Var:
+			// A parameter is checked at the helper's call sites
+			if fn, index := paramOf(pass, obj); fn != nil {
+				return p.checkParamGoroutine(pass, obj, fn, index)
+			}
 
-		// Check all assignments - if any don't have the defer, report error
-		for _, funcLit := range funcLits {
-			if err := p.checkGoroutine(funcLit.Body, pass.TypesInfo); err != nil {
-				return err
+			// This is a variable, try to find all its function assignments
+			values := p.findAllFunctionAssignments(pass, obj)
+			if len(values) == 0 {
+				break
 			}
-		}
-		// All assignments are valid
-		return nil
-	case *ast.SelectorExpr:
-		funcName = e.Sel.Name
-		// Determine the static type of the receiver expression directly
-		// This works for identifiers, field selectors, and parenthesized forms.
-		if t := pass.TypesInfo.TypeOf(e.X); t != nil {
-			receiverType = t
-		} else if ident, ok := e.X.(*ast.Ident); ok {
-			// Fallback to object-based lookup for identifiers
-			if obj := pass.TypesInfo.ObjectOf(ident); obj != nil {
-				if varObj, ok := obj.(*types.Var); ok {
-					receiverType = varObj.Type()
+
+			// Check all assignments - if any don't have the defer, report error
+			for _, value := range values {
+				if lit, ok := value.(*ast.FuncLit); ok {
+					if err := p.checkGoroutine(pass, lit.Body, pass.TypesInfo); err != nil {
+						return err
+					}
+					continue
+				}
+				// Named functions and method values go through the definition check
+				if err := p.checkGoroutineDefinition(pass, value, callPos); err != nil {
+					return errors.Wrapf(err, "%s set to %s", obj.Name(), types.ExprString(value))
 				}
 			}
+			// All assignments are valid
+			return nil
 		}
-
+	case *ast.SelectorExpr:
 		// If the receiver is an interface, verify all concrete implementations
-		if receiverType != nil {
-			if _, isInterface := receiverType.Underlying().(*types.Interface); isInterface {
-				if err := p.checkInterfaceMethodCall(pass, funcName, receiverType, callPos); err != nil {
-					p.logger.Printf("cannot verify interface method call method=%s interface=%s reason=%s", funcName, receiverType.String(), err.Error())
-					// Don't report an error for interface calls we can't verify
-					return nil
-				}
+		if receiverType := pass.TypesInfo.TypeOf(e.X); receiverType != nil && types.IsInterface(receiverType) {
+			if err := p.checkInterfaceMethodCall(pass, e.Sel.Name, receiverType, callPos); err != nil {
+				p.logger.Printf("cannot verify interface method call method=%s interface=%s reason=%s", e.Sel.Name, receiverType.String(), err.Error())
+				// Don't report an error for interface calls we can't verify
 				return nil
 			}
+			return nil
 		}
 
-		// Try to resolve external method or package-qualified function via type info
+		// Method values and expressions, including promoted methods and
+		// pointer receivers, resolve through their selection; package-qualified
+		// functions through the selector identifier
 		if sel := pass.TypesInfo.Selections[e]; sel != nil {
-			if fn, ok := sel.Obj().(*types.Func); ok {
-				if fn.Pkg() != nil && pass.Pkg != nil && fn.Pkg().Path() != pass.Pkg.Path() {
-					if err := p.checkExternalFunc(pass, fn); err != nil {
-						return err
-					}
-					return nil
-				}
+			if field, ok := sel.Obj().(*types.Var); ok {
+				// a func-valued field: check what the package stores in it
+				return p.checkFieldGoroutine(pass, field, callPos)
 			}
+			fn, _ = sel.Obj().(*types.Func)
 		} else {
-			// Not a method selection; this is likely a package-qualified function call: pkg.Func()
-			if obj, ok := pass.TypesInfo.Uses[e.Sel].(*types.Func); ok {
-				if obj.Pkg() != nil && pass.Pkg != nil && obj.Pkg().Path() != pass.Pkg.Path() {
-					if err := p.checkExternalFunc(pass, obj); err != nil {
-						return err
-					}
-					return nil
-				}
-			}
+			fn, _ = pass.TypesInfo.Uses[e.Sel].(*types.Func)
 		}
 	default:
 		return errors.New("unsupported function expression type")
 	}
 
-	// Find the function declaration using AST traversal
-	for _, file := range pass.Files {
-		var body *ast.BlockStmt
-
-		ast.Inspect(file, func(n ast.Node) bool {
-			switch node := n.(type) {
-			case *ast.FuncDecl:
-				// Check if this is the function we're looking for
-				if node.Name.Name != funcName {
-					break
-				}
+	if fn == nil {
+		return errors.New("could not find function body")
+	}
+	// methods of instantiated types are declared on the generic type
+	fn = fn.Origin()
 
-				// If we're looking for a method, check the receiver
-				if receiverType != nil && node.Recv != nil && len(node.Recv.List) > 0 {
-					// For methods, we need to match the receiver type
-					for _, field := range node.Recv.List {
-						// Get the type from the declaration using TypesInfo
-						declType := pass.TypesInfo.TypeOf(field.Type)
-						if declType != nil && types.Identical(receiverType, declType) {
-							body = node.Body
-							return false
-						}
-					}
-				} else if receiverType == nil && node.Recv == nil {
-					// For regular functions, no receiver should be present
-					body = node.Body
-					return false
-				}
-			}
-			return true
-		})
+	if fn.Pkg() != nil && pass.Pkg != nil && fn.Pkg().Path() != pass.Pkg.Path() {
+		return p.checkExternalFunc(pass, fn)
+	}
 
-		if body != nil {
-			return p.checkGoroutine(body, pass.TypesInfo)
-		}
+	// Map the object to its declaration by position
+	if decl := findFuncDecl(pass, fn); decl != nil {
+		return p.checkGoroutine(pass, decl.Body, pass.TypesInfo)
 	}
 
 	return errors.New("could not find function body")
 }
 
+// reportOnce reports a diagnostic unless an identical one was already
+// reported, as happens for findings in a body started from several goroutines.
+func (p *Analyzer) reportOnce(pass *analysis.Pass, diagnostic analysis.Diagnostic) {
+	key := fmt.Sprintf("%d:%s", diagnostic.Pos, diagnostic.Message)
+	if _, loaded := p.reportedDiagnostics.LoadOrStore(key, struct{}{}); !loaded {
+		pass.Report(diagnostic)
+	}
+}
+
+// guardError is a finding that carries its own diagnostic instead of being
+// reported as a missing defer at the goroutine. Diagnostics without a position
+// refer to code outside the analyzed package and are reported at the call.
+type guardError struct {
+	diagnostic analysis.Diagnostic
+}
+
+func (e *guardError) Error() string {
+	return e.diagnostic.Message
+}
+
 func (p *Analyzer) logLinterError(pass *analysis.Pass, errPos token.Pos, callPos token.Pos, err error) {
+	var errs linterErrors
+	if errors.As(err, &errs) {
+		for _, err := range errs {
+			p.logLinterError(pass, errPos, callPos, err)
+		}
+		return
+	}
+
 	errPosition := pass.Fset.Position(errPos)
 	message := fmt.Sprintf("missing %s()", p.targetDescription())
 	p.logger.Printf("%s uri=%s details=%s", message, utils.URI(errPosition.Filename, errPosition.Line), err.Error())
 
+	var gerr *guardError
+	if errors.As(err, &gerr) {
+		diagnostic := gerr.diagnostic
+		if diagnostic.Pos == token.NoPos {
+			diagnostic.Pos = callPos
+		}
+		p.reportOnce(pass, diagnostic)
+		return
+	}
+
 	if callPos == errPos {
 		pass.Reportf(errPos, "missing defer call to %s: %s", p.targetDescription(), err.Error())
 	} else {
@@ -344,10 +585,7 @@ func (p *Analyzer) logLinterError(pass *analysis.Pass, errPos token.Pos, callPos
 }
 
 func (p *Analyzer) targetDescription() string {
-	if p.target.PackagePath == "" {
-		return p.target.FuncName
-	}
-	return fmt.Sprintf("%s.%s", p.target.PackagePath, p.target.FuncName)
+	return p.targets.Description()
 }
 
 // checkInterfaceMethodCall attempts to find and verify all concrete implementations
@@ -399,7 +637,7 @@ func (p *Analyzer) checkInterfaceMethodCall(pass *analysis.Pass, methodName stri
 		// no-op: keep parallel arrays consistent if needed later
 	}
 	for i, impl := range implementations {
-		if err := p.checkGoroutine(impl.Body, pass.TypesInfo); err != nil {
+		if err := p.checkGoroutine(pass, impl.Body, pass.TypesInfo); err != nil {
 			// Report: error position is implementation method, call position is the goroutine call site
 			_ = implementationTypes[i] // reserved for future message enrichment
 			p.logLinterError(pass, impl.Pos(), callPos, err)
@@ -411,11 +649,13 @@ func (p *Analyzer) checkInterfaceMethodCall(pass *analysis.Pass, methodName stri
 	return nil
 }
 
-// findAllFunctionLiteralAssignments finds ALL function literal assignments to a variable.
+// findAllFunctionAssignments finds ALL function assignments to a variable:
+// function literals, named functions and method values, in local and
+// package-level declarations across the package's files.
 // It uses TypesInfo to ensure we match the exact variable object, not just any variable with the same name.
 // This is important because a variable can be reassigned, and we need to check all possible values.
-func (p *Analyzer) findAllFunctionLiteralAssignments(pass *analysis.Pass, varObj *types.Var) []*ast.FuncLit {
-	var funcLits []*ast.FuncLit
+func (p *Analyzer) findAllFunctionAssignments(pass *analysis.Pass, varObj *types.Var) []ast.Expr {
+	var values []ast.Expr
 
 	for _, file := range pass.Files {
 		ast.Inspect(file, func(n ast.Node) bool {
@@ -443,15 +683,12 @@ func (p *Analyzer) findAllFunctionLiteralAssignments(pass *analysis.Pass, varObj
 						continue
 					}
 
-					lit, ok := node.Rhs[i].(*ast.FuncLit)
-					if !ok {
-						continue
+					if value := functionValue(pass, node.Rhs[i]); value != nil {
+						values = append(values, value)
 					}
-
-					funcLits = append(funcLits, lit)
 				}
 			case *ast.ValueSpec:
-				// Check variable declarations like: var x = func() {}
+				// Check variable declarations like: var x = func() {} or var x = worker
 				for i, name := range node.Names {
 					// Use Defs to get the object being defined here
 					if pass.TypesInfo.Defs[name] != varObj {
@@ -462,19 +699,38 @@ func (p *Analyzer) findAllFunctionLiteralAssignments(pass *analysis.Pass, varObj
 						continue
 					}
 
-					lit, ok := node.Values[i].(*ast.FuncLit)
-					if !ok {
-						continue
+					if value := functionValue(pass, node.Values[i]); value != nil {
+						values = append(values, value)
 					}
-
-					funcLits = append(funcLits, lit)
 				}
 			}
 			return true
 		})
 	}
 
-	return funcLits
+	return values
+}
+
+// functionValue returns expr, without parentheses, when it is a function
+// literal, a named function or a method value, and nil otherwise.
+func functionValue(pass *analysis.Pass, expr ast.Expr) ast.Expr {
+	switch e := ast.Unparen(expr).(type) {
+	case *ast.FuncLit:
+		return e
+	case *ast.Ident, *ast.IndexExpr, *ast.IndexListExpr:
+		if calleeFunc(e, pass.TypesInfo) != nil {
+			return e
+		}
+	case *ast.SelectorExpr:
+		if sel := pass.TypesInfo.Selections[e]; sel != nil {
+			if sel.Kind() == types.MethodVal {
+				return e
+			}
+		} else if calleeFunc(e, pass.TypesInfo) != nil {
+			return e
+		}
+	}
+	return nil
 }
 
 // checkExternalFunc attempts to load the defining package for the given function
@@ -490,7 +746,7 @@ func (p *Analyzer) checkExternalFunc(pass *analysis.Pass, fn *types.Func) error
 	if body == nil {
 		return nil
 	}
-	return p.checkGoroutine(body, typeInfo)
+	return p.checkGoroutine(pass, body, typeInfo)
 }
 
 // findFuncBodyInObjectPackage loads the package where the function is defined and
@@ -500,6 +756,8 @@ func (p *Analyzer) findFuncBodyInObjectPackage(fn *types.Func) (*ast.BlockStmt,
 		return nil, nil, errors.New("function has no package")
 	}
 
+	// methods of instantiated types are declared on the generic type
+	fn = fn.Origin()
 	pkgPath := fn.Pkg().Path()
 	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo}
 	pkgs, err := packages.Load(cfg, pkgPath)
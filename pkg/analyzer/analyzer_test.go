@@ -1,10 +1,17 @@
 package analyzer
 
 import (
+	"fmt"
+	"go/token"
 	"log"
+	"path/filepath"
+	"reflect"
 	"testing"
 
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/analysistest"
+	"golang.org/x/tools/go/analysis/checker"
+	"golang.org/x/tools/go/packages"
 )
 
 func TestMethods(t *testing.T) {
@@ -27,3 +34,509 @@ func TestCustomTarget(t *testing.T) {
 
 	analysistest.Run(t, analysistest.TestData(), a, "custompattern")
 }
+
+func TestMultipleTargets(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "multitarget/common.HandlePanic"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+	if err := a.Flags.Set("target", "multitarget/sentryutil.Recover,multitarget/testutil.CatchPanic"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "multitarget")
+}
+
+func TestMethodTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "(*methodtarget/obs.Reporter).Recover"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "methodtarget")
+}
+
+func TestInterfaceTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "ifacetarget/obs.PanicHandler.Handle"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "ifacetarget")
+}
+
+func TestFactoryTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "factory/guard.Recover(),(*factory/guard.Guard).Recover()"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "factory")
+}
+
+func TestPatternTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "*/panics.Handle*,*/panics.*.Handle*"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "pattern")
+}
+
+func TestRegexpTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", `re:^pattern/team[ab]/panics\.Handle$`); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "regexppattern")
+}
+
+func TestRegexpQuantifierTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	// the comma in {1,2} belongs to the pattern, the last one separates targets
+	if err := a.Flags.Set("target", `re:^pattern/team[a-z]{1,2}/panics\.Handle$,pattern/legacy.Handle`); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "regexpquantifier")
+}
+
+func TestHandlerCallsRecover(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("verify-handlers", "true"); err != nil {
+		t.Fatalf("set verify-handlers flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a,
+		"handlercheck/norecover", "handlercheck/nested", "handlercheck/discarded", "handlercheck/nilcheck")
+}
+
+func TestHandlerFactoryCallsRecover(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "Recover(),Guard()"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+	if err := a.Flags.Set("verify-handlers", "true"); err != nil {
+		t.Fatalf("set verify-handlers flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "handlercheck/factory")
+}
+
+func TestHandlerInOtherPackage(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "handlercheck/remote/handler.HandlePanic"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+	if err := a.Flags.Set("verify-handlers", "true"); err != nil {
+		t.Fatalf("set verify-handlers flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "handlercheck/remote")
+}
+
+func TestTargetList(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value string
+		want  []string
+		err   bool
+	}{
+		{value: "pkg/a.Handle,(*pkg/b.Reporter).Recover", want: []string{"pkg/a.Handle", "(*pkg/b.Reporter).Recover"}},
+		{value: `re:^pkg/team[a-z]{1,2}\.Handle$,pkg/a.Handle`, want: []string{`re:^pkg/team[a-z]{1,2}\.Handle$`, "pkg/a.Handle"}},
+		{value: `re:^pkg/a\.Handle\($,pkg/b.Handle`, want: []string{`re:^pkg/a\.Handle\($`, "pkg/b.Handle"}},
+		{value: `re:^pkg/a\[,pkg/b.Handle`, want: []string{`re:^pkg/a\[`, "pkg/b.Handle"}},
+		{value: "pkg/a.Recover()", want: []string{"pkg/a.Recover()"}},
+		{value: "()", err: true},
+		{value: "pkg/a.Handle,()", err: true},
+		{value: "pkg/a.", err: true},
+	} {
+		var targets Targets
+		err := targets.Set(tc.value)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Set(%q): expected an error, got %v", tc.value, targets)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q): unexpected error: %v", tc.value, err)
+			continue
+		}
+		var got []string
+		for _, target := range targets {
+			got = append(got, target.String())
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Set(%q): got %q, want %q", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestMissingTarget(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "missingtarget/common.HandlPanic,missingtarget/comon.HandlePanic,(*missingtarget/common.Reporter).Recovr"); err != nil {
+		t.Fatalf("set Target flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "missingtarget", "missingtarget/common")
+}
+
+func TestDeferredWrapper(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "wrapper")
+}
+
+func TestInlineRecover(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("reporter", "inlinerecover/obs.Report"); err != nil {
+		t.Fatalf("set reporter flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "inlinerecover")
+}
+
+func TestLabelPolicy(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	for flag, value := range map[string]string{
+		"label-arg":     "0",
+		"label-pattern": "^[a-z]+(-[a-z]+)*$",
+		"label-unique":  "package",
+	} {
+		if err := a.Flags.Set(flag, value); err != nil {
+			t.Fatalf("set %s flag: %v", flag, err)
+		}
+	}
+
+	results := analysistest.Run(t, analysistest.TestData(), a, "labels")
+	checkRelated(t, results, map[string][]string{
+		"labels.go:38": {"labels.go:43"},
+		"labels.go:43": {"labels.go:38"},
+	})
+}
+
+func TestLabelUniqueModule(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	for flag, value := range map[string]string{
+		"label-arg":    "0",
+		"label-unique": "module",
+	} {
+		if err := a.Flags.Set(flag, value); err != nil {
+			t.Fatalf("set %s flag: %v", flag, err)
+		}
+	}
+
+	results := analysistest.Run(t, analysistest.TestData(), a, "labelsmodule/second", "labelsmodule/app")
+	checkRelated(t, results, map[string][]string{
+		"second.go:13": {"first.go:13"},
+		"app.go:1":     {"other.go:13", "second.go:18"},
+	})
+}
+
+// checkRelated compares the related information of the diagnostics that have
+// any with want, keyed and valued by file base name and line.
+func checkRelated(t *testing.T, results []*analysistest.Result, want map[string][]string) {
+	t.Helper()
+
+	got := map[string][]string{}
+	for _, result := range results {
+		fset := result.Action.Package.Fset
+		for _, diagnostic := range result.Action.Diagnostics {
+			if len(diagnostic.Related) == 0 {
+				continue
+			}
+			key := shortPosition(fset.Position(diagnostic.Pos))
+			for _, related := range diagnostic.Related {
+				got[key] = append(got[key], shortPosition(fset.Position(related.Pos)))
+			}
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("related information: got %v, want %v", got, want)
+	}
+}
+
+func shortPosition(position token.Position) string {
+	return fmt.Sprintf("%s:%d", filepath.Base(position.Filename), position.Line)
+}
+
+func TestPrologue(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("prologue", "(*sync.WaitGroup).Done,runtime.LockOSThread,runtime.UnlockOSThread,runtime/pprof.WithLabels,runtime/pprof.Labels,runtime/pprof.SetGoroutineLabels"); err != nil {
+		t.Fatalf("set prologue flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "prologue")
+}
+
+func TestFlow(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("ssa", "true"); err != nil {
+		t.Fatalf("set ssa flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "flow")
+}
+
+func TestExemptPanicFree(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("exempt-panic-free", "true"); err != nil {
+		t.Fatalf("set exempt-panic-free flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "exempt")
+}
+
+func TestTrampoline(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.Run(t, analysistest.TestData(), a, "trampoline")
+}
+
+func TestSteps(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	for _, value := range []string{"runtime/pprof.SetGoroutineLabels", "steps/trace.Start,defer:(*steps/trace.Span).End"} {
+		if err := a.Flags.Set("step", value); err != nil {
+			t.Fatalf("set step flag: %v", err)
+		}
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "steps")
+}
+
+func TestDeferOrder(t *testing.T) {
+	t.Parallel()
+
+	for policy, pkg := range map[string]string{
+		"allowlist": "deferorder/allowlist",
+		"strict":    "deferorder/strict",
+	} {
+		for _, ssa := range []string{"false", "true"} {
+			t.Run(policy+"/ssa="+ssa, func(t *testing.T) {
+				t.Parallel()
+
+				logger := log.Default()
+				a := New(logger)
+				for flag, value := range map[string]string{
+					"ssa":         ssa,
+					"defer-order": policy,
+					"defer-allow": "(*sync.WaitGroup).Done,deferorder/allowlist.release",
+				} {
+					if err := a.Flags.Set(flag, value); err != nil {
+						t.Fatalf("set %s flag: %v", flag, err)
+					}
+				}
+
+				analysistest.Run(t, analysistest.TestData(), a, pkg)
+			})
+		}
+	}
+}
+
+func TestDeadGuards(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("dead-guards", "true"); err != nil {
+		t.Fatalf("set dead-guards flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "deadguard")
+}
+
+func TestDeadGuardsInProgram(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("dead-guards", "true"); err != nil {
+		t.Fatalf("set dead-guards flag: %v", err)
+	}
+
+	results := analysistest.Run(t, analysistest.TestData(), a, "deadguardmodule/cmd")
+	checkRelated(t, results, map[string][]string{
+		"main.go:1":  {"lib.go:12"},
+		"main.go:21": {"lib.go:22"},
+	})
+}
+
+func TestBypass(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("bypass", "os.Exit,log.Fatal*,log.Logger.Fatal*,runtime.Goexit"); err != nil {
+		t.Fatalf("set bypass flag: %v", err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "bypass")
+}
+
+func TestCompetingRecover(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("reporter", "competing.Report"); err != nil {
+		t.Fatal(err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "competing")
+}
+
+func TestNonDeferred(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "nondeferred.HandlePanic,nondeferred.Recovered"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Flags.Set("non-deferred", "true"); err != nil {
+		t.Fatalf("set non-deferred flag: %v", err)
+	}
+
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), a, "nondeferred")
+}
+
+func TestGenerics(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+	if err := a.Flags.Set("target", "generics.HandlePanic[T]"); err != nil {
+		t.Fatal(err)
+	}
+
+	analysistest.Run(t, analysistest.TestData(), a, "generics")
+}
+
+func TestSelection(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.Run(t, analysistest.TestData(), a, "selection")
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.Run(t, analysistest.TestData(), a, "fields")
+}
+
+func TestFuncVars(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.Run(t, analysistest.TestData(), a, "funcvars")
+}
+
+func TestParams(t *testing.T) {
+	t.Parallel()
+
+	logger := log.Default()
+	a := New(logger)
+
+	analysistest.Run(t, analysistest.TestData(), a, "params")
+}
+
+// TestStandardLibrary runs the analyzer over a standard library package whose
+// tests use syntax the SSA builder cannot handle: without -ssa no SSA must be
+// built, and with it the analyzer must fall back to the structural check.
+func TestStandardLibrary(t *testing.T) {
+	t.Parallel()
+
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.LoadAllSyntax, Tests: true}, "database/sql")
+	if err != nil {
+		t.Fatalf("load database/sql: %v", err)
+	}
+	for _, ssa := range []string{"false", "true"} {
+		t.Run("ssa="+ssa, func(t *testing.T) {
+			a := New(nil)
+			if err := a.Flags.Set("ssa", ssa); err != nil {
+				t.Fatalf("set ssa flag: %v", err)
+			}
+
+			graph, err := checker.Analyze([]*analysis.Analyzer{a}, pkgs, nil)
+			if err != nil {
+				t.Fatalf("analyze database/sql: %v", err)
+			}
+			for _, root := range graph.Roots {
+				if root.Err != nil {
+					t.Errorf("analyze %s: %v", root.Package.PkgPath, root.Err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// maxBypassDepth bounds how deep same-package helpers are followed.
+const maxBypassDepth = 8
+
+// checkBypasses reports calls in a guarded goroutine body, directly or
+// through helpers of the analyzed package, to functions that terminate
+// without a panic and so give the handler nothing to report.
+func (p *Analyzer) checkBypasses(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) []error {
+	if len(p.bypasses) == 0 {
+		return nil
+	}
+
+	var errs []error
+	p.findBypasses(pass, body, typeInfo, nil, map[*types.Func]bool{}, func(chain []bypassLink) {
+		names := make([]string, 0, len(chain))
+		for _, link := range chain {
+			names = append(names, link.name)
+		}
+		message := fmt.Sprintf("call to %s bypasses the handler: it terminates without a panic for the handler to recover",
+			names[len(names)-1])
+		if len(chain) > 1 {
+			message += fmt.Sprintf(" (call chain: %s)", strings.Join(names, " -> "))
+		}
+
+		diagnostic := analysis.Diagnostic{Category: "bypass", Message: message}
+		if typeInfo == pass.TypesInfo {
+			diagnostic.Pos = chain[0].pos
+			for _, link := range chain[1:] {
+				diagnostic.Related = append(diagnostic.Related, analysis.RelatedInformation{
+					Pos:     link.pos,
+					Message: "calls " + link.name,
+				})
+			}
+		}
+		errs = append(errs, &guardError{diagnostic: diagnostic})
+	})
+	return errs
+}
+
+// bypassLink is one call on the way to a bypass.
+type bypassLink struct {
+	name string
+	pos  token.Pos
+}
+
+func (p *Analyzer) findBypasses(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info, chain []bypassLink,
+	visiting map[*types.Func]bool, report func(chain []bypassLink)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit, *ast.GoStmt:
+			// literals run elsewhere, if at all; new goroutines are checked on their own
+			return false
+		case *ast.CallExpr:
+			fn := calleeFunc(node.Fun, typeInfo)
+			if fn == nil {
+				return true
+			}
+			if p.matchFuncObject(p.bypasses, fn, false) == nil {
+				report(append(chain[:len(chain):len(chain)], bypassLink{name: fn.FullName(), pos: node.Pos()}))
+				return true
+			}
+
+			// follow helpers of the analyzed package
+			if typeInfo != pass.TypesInfo || visiting[fn] || len(chain) >= maxBypassDepth {
+				return true
+			}
+			decl := findFuncDecl(pass, fn)
+			if decl == nil {
+				return true
+			}
+			visiting[fn] = true
+			next := append(chain[:len(chain):len(chain)], bypassLink{name: fn.Name(), pos: node.Pos()})
+			p.findBypasses(pass, decl.Body, typeInfo, next, visiting, report)
+			delete(visiting, fn)
+		}
+		return true
+	})
+}
@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkCompetingRecovers reports recover() calls that swallow a panic before
+// the handler sees it: in functions the guarded goroutine defers after the
+// handler, which run first, and in functions deferred by helpers it calls
+// directly. Recovers that pass the value to a reporter are accepted.
+func (p *Analyzer) checkCompetingRecovers(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) []error {
+	local := typeInfo == pass.TypesInfo
+	handler := p.handlerDefer(body, typeInfo)
+	var errs []error
+	report := func(call *ast.CallExpr, format string, args ...any) {
+		message := fmt.Sprintf(format, args...) + fmt.Sprintf(", so %s never sees it", p.targetDescription())
+		if len(p.reporters) > 0 {
+			message += fmt.Sprintf("; pass the recovered value to %s", p.reporters.Description())
+		}
+		diagnostic := analysis.Diagnostic{Category: "competing-recover", Message: message}
+		if local {
+			diagnostic.Pos = call.Pos()
+			diagnostic.End = call.End()
+		}
+		errs = append(errs, &guardError{diagnostic: diagnostic})
+	}
+
+	for _, deferStmt := range deferStmts(body) {
+		if deferStmt == handler || (handler != nil && deferStmt.Pos() < handler.Pos()) {
+			continue
+		}
+		for _, call := range p.swallowingRecovers(pass, deferStmt, typeInfo) {
+			report(call, "recover() in a function deferred after the handler runs first and swallows the panic")
+		}
+	}
+
+	if !local {
+		return errs
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit, *ast.GoStmt, *ast.DeferStmt:
+			return false
+		case *ast.CallExpr:
+			fn := calleeFunc(node.Fun, typeInfo)
+			if fn == nil || p.matchFuncObject(p.targets, fn, false) == nil {
+				return true
+			}
+			decl := findFuncDecl(pass, fn)
+			if decl == nil {
+				return true
+			}
+			for _, deferStmt := range deferStmts(decl.Body) {
+				for _, call := range p.swallowingRecovers(pass, deferStmt, typeInfo) {
+					report(call, "recover() deferred in %s, which the guarded goroutine calls, swallows the panic", fn.Name())
+				}
+			}
+		}
+		return true
+	})
+	return errs
+}
+
+// deferStmts lists the defer statements of body outside nested literals.
+func deferStmts(body *ast.BlockStmt) []*ast.DeferStmt {
+	var stmts []*ast.DeferStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			stmts = append(stmts, node)
+		}
+		return true
+	})
+	return stmts
+}
+
+// swallowingRecovers returns the recover() calls made directly by the function
+// deferStmt defers, a literal or a function of the analyzed package other
+// than a handler, that do not forward the value to a reporter.
+func (p *Analyzer) swallowingRecovers(pass *analysis.Pass, deferStmt *ast.DeferStmt, typeInfo *types.Info) []*ast.CallExpr {
+	var body *ast.BlockStmt
+	switch fun := ast.Unparen(deferStmt.Call.Fun).(type) {
+	case *ast.FuncLit:
+		body = fun.Body
+	default:
+		fn := calleeFunc(fun, typeInfo)
+		if fn == nil || typeInfo != pass.TypesInfo || p.matchFuncObject(p.targets, fn, false) == nil {
+			return nil
+		}
+		if decl := findFuncDecl(pass, fn); decl != nil {
+			body = decl.Body
+		}
+	}
+	if body == nil {
+		return nil
+	}
+
+	var calls []*ast.CallExpr
+	for _, call := range directRecoverCalls(body, typeInfo) {
+		if len(p.reporters) > 0 && p.forwardsRecoveredValue(body, call, typeInfo) {
+			continue
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
@@ -0,0 +1,535 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// guardedFunc is a function declared in the analyzed package that defers the
+// handler in its top-level statements.
+type guardedFunc struct {
+	decl    *ast.FuncDecl
+	handler *ast.DeferStmt
+	// started is set when a go statement, directly or through a trampoline,
+	// starts the function; escapes when it is used as a value and may be
+	// started anywhere.
+	started bool
+	escapes bool
+	// called is set when the function is called synchronously.
+	called bool
+	// guardedCallers are synchronous call sites inside functions that defer
+	// the handler themselves.
+	guardedCallers []token.Pos
+}
+
+// guardSite is an exported function that defers the handler, as recorded in
+// a guardsFact.
+type guardSite struct {
+	// Func is the full name of the function, Name its name alone for methods
+	// started through an interface.
+	Func    string
+	Name    string
+	Method  bool
+	Handler string
+	Pos     token.Position
+	End     token.Position
+}
+
+// guardsFact summarizes the exported guarded functions of a package and of
+// the packages it imports, with the ones started, called synchronously or
+// already reported for a guarded caller so far. A main package sees the whole
+// program through it and reports the guards it calls but never starts.
+type guardsFact struct {
+	Guards  []guardSite
+	Started []string
+	Called  []string
+	Nested  []string
+}
+
+func (*guardsFact) AFact() {}
+
+func (f *guardsFact) String() string {
+	var names []string
+	for _, guard := range f.Guards {
+		names = append(names, guard.Func)
+	}
+	return "guards " + strings.Join(names, ", ")
+}
+
+// importGuards merges the guardsFact of the direct imports of the package.
+func importGuards(pass *analysis.Pass) *guardsFact {
+	merged := &guardsFact{}
+	for _, imp := range pass.Pkg.Imports() {
+		var fact guardsFact
+		if pass.ImportPackageFact(imp, &fact) {
+			merged.merge(&fact)
+		}
+	}
+	return merged
+}
+
+func (f *guardsFact) merge(other *guardsFact) {
+	for _, guard := range other.Guards {
+		if !slices.ContainsFunc(f.Guards, func(g guardSite) bool { return g.Func == guard.Func }) {
+			f.Guards = append(f.Guards, guard)
+		}
+	}
+	for _, name := range other.Started {
+		f.start(name)
+	}
+	for _, name := range other.Called {
+		f.call(name)
+	}
+	for _, name := range other.Nested {
+		f.nest(name)
+	}
+}
+
+func (f *guardsFact) start(name string) {
+	if !slices.Contains(f.Started, name) {
+		f.Started = append(f.Started, name)
+	}
+}
+
+func (f *guardsFact) call(name string) {
+	if !slices.Contains(f.Called, name) {
+		f.Called = append(f.Called, name)
+	}
+}
+
+func (f *guardsFact) nest(name string) {
+	if !slices.Contains(f.Nested, name) {
+		f.Nested = append(f.Nested, name)
+	}
+}
+
+// reportDeadGuards reports handler defers that cannot do their job: in
+// functions never started as goroutines, where they swallow panics the caller
+// should see, and in functions called from code that is already guarded.
+// Unexported functions are checked per package. Exported functions may be
+// started by other packages, so they are recorded in a guardsFact: packages
+// importing them report calls from guarded code, and a main package reports
+// the ones the program calls but never starts. Functions whose value escapes
+// may be started anywhere and are only checked for guarded callers.
+// Functions stored in fields, parameters and variables the package only calls
+// are followed through the go statements that start them.
+func (p *Analyzer) reportDeadGuards(pass *analysis.Pass, inspected *inspector.Inspector) {
+	if !p.deadGuards {
+		return
+	}
+
+	guarded := map[*types.Func]*guardedFunc{}
+	var order []*types.Func
+	for _, file := range pass.Files {
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Body == nil {
+				continue
+			}
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			if handler := p.handlerDefer(decl.Body, pass.TypesInfo); handler != nil {
+				guarded[fn] = &guardedFunc{decl: decl, handler: handler}
+				order = append(order, fn)
+			}
+		}
+	}
+	program := importGuards(pass)
+	remote := map[string]guardSite{}
+	for _, guard := range program.Guards {
+		remote[guard.Func] = guard
+	}
+	if len(guarded) == 0 && len(remote) == 0 {
+		return
+	}
+
+	// calls maps callee identifiers to their call, to tell calls from values
+	calls := map[*ast.Ident]*ast.CallExpr{}
+	inspected.WithStack([]ast.Node{(*ast.CallExpr)(nil), (*ast.GoStmt)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		if goStmt, ok := n.(*ast.GoStmt); ok {
+			if method := calleeFunc(goStmt.Call.Fun, pass.TypesInfo); method != nil && isInterfaceMethod(method) {
+				// any implementation may be started through the interface
+				for _, g := range guarded {
+					if g.decl.Recv != nil && g.decl.Name.Name == method.Name() {
+						g.started = true
+					}
+				}
+				for _, guard := range remote {
+					if guard.Method && guard.Name == method.Name() {
+						program.start(guard.Func)
+					}
+				}
+			}
+			for _, fn := range p.startedFuncs(pass, goStmt.Call.Fun, map[*types.Var]bool{}) {
+				if g := guarded[fn]; g != nil {
+					g.started = true
+				} else if _, ok := remote[fn.FullName()]; ok {
+					program.start(fn.FullName())
+				}
+			}
+			return true
+		}
+
+		call := n.(*ast.CallExpr)
+		ident := calleeIdent(call.Fun)
+		if ident == nil {
+			return true
+		}
+		calls[ident] = call
+		fn := calleeFunc(call.Fun, pass.TypesInfo)
+		g := guarded[fn]
+		guard, isRemote := guardSite{}, false
+		if g == nil && fn != nil {
+			guard, isRemote = remote[fn.FullName()]
+		}
+		if g == nil && !isRemote {
+			return true
+		}
+		if goStmt, ok := stack[len(stack)-2].(*ast.GoStmt); ok && goStmt.Call == call {
+			if g != nil {
+				g.started = true
+			} else {
+				program.start(guard.Func)
+			}
+			return true
+		}
+		if g != nil {
+			g.called = true
+		} else {
+			program.call(guard.Func)
+		}
+		body := enclosingBody(stack)
+		if body == nil || p.handlerDefer(body, pass.TypesInfo) == nil {
+			return true
+		}
+		if g != nil && body != g.decl.Body {
+			g.guardedCallers = append(g.guardedCallers, call.Pos())
+		}
+		if isRemote {
+			program.nest(guard.Func)
+			diagnostic := analysis.Diagnostic{
+				Pos:      call.Pos(),
+				End:      call.End(),
+				Category: "dead-guard",
+				Message: fmt.Sprintf("%s deferred in %s is nested inside a guarded caller: it swallows panics before the caller's handler sees them",
+					guard.Handler, guard.Func),
+			}
+			if pos := filePos(pass.Fset, guard.Pos); pos.IsValid() {
+				diagnostic.Related = []analysis.RelatedInformation{{Pos: pos, End: filePos(pass.Fset, guard.End), Message: "deferred here"}}
+			}
+			p.reportOnce(pass, diagnostic)
+		}
+		return true
+	})
+
+	held := p.heldValues(pass)
+	for ident, obj := range pass.TypesInfo.Uses {
+		fn, ok := obj.(*types.Func)
+		if !ok || calls[ident] != nil || held[ident] {
+			continue
+		}
+		if g := guarded[fn]; g != nil {
+			g.escapes = true
+		} else if _, ok := remote[fn.Origin().FullName()]; ok {
+			program.start(fn.Origin().FullName())
+		}
+	}
+
+	for _, fn := range order {
+		g := guarded[fn]
+		name := funcDeclName(g.decl)
+		handler := types.ExprString(g.handler.Call.Fun)
+		if len(g.guardedCallers) > 0 {
+			diagnostic := analysis.Diagnostic{
+				Pos:      g.handler.Pos(),
+				End:      g.handler.End(),
+				Category: "dead-guard",
+				Message: fmt.Sprintf("%s deferred in %s is nested inside a guarded caller: it swallows panics before the caller's handler sees them",
+					handler, name),
+			}
+			for _, pos := range g.guardedCallers {
+				diagnostic.Related = append(diagnostic.Related, analysis.RelatedInformation{Pos: pos, Message: "called here from guarded code"})
+			}
+			p.reportOnce(pass, diagnostic)
+			continue
+		}
+		if isProgramEntry(g.decl) {
+			continue
+		}
+		if fn.Exported() {
+			program.Guards = append(program.Guards, guardSite{
+				Func:    fn.FullName(),
+				Name:    fn.Name(),
+				Method:  g.decl.Recv != nil,
+				Handler: handler,
+				Pos:     pass.Fset.Position(g.handler.Pos()),
+				End:     pass.Fset.Position(g.handler.End()),
+			})
+			if g.started || g.escapes {
+				program.start(fn.FullName())
+			}
+			if g.called {
+				program.call(fn.FullName())
+			}
+			continue
+		}
+		if g.started || g.escapes {
+			continue
+		}
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:      g.handler.Pos(),
+			End:      g.handler.End(),
+			Category: "dead-guard",
+			Message: fmt.Sprintf("%s deferred in %s, which is never started as a goroutine: called synchronously it swallows panics its callers should see",
+				handler, name),
+		})
+	}
+
+	if pass.Pkg.Name() == "main" {
+		p.reportProgramDeadGuards(pass, program)
+	} else if len(program.Guards) > 0 {
+		pass.ExportPackageFact(program)
+	}
+}
+
+// reportProgramDeadGuards reports, at the package clause of a main package,
+// the exported guarded functions the program calls synchronously but never
+// starts as goroutines, unless a guarded caller was reported already.
+func (p *Analyzer) reportProgramDeadGuards(pass *analysis.Pass, program *guardsFact) {
+	for _, guard := range program.Guards {
+		if !slices.Contains(program.Called, guard.Func) || slices.Contains(program.Started, guard.Func) ||
+			slices.Contains(program.Nested, guard.Func) {
+			continue
+		}
+		diagnostic := analysis.Diagnostic{
+			Pos:      pass.Files[0].Package,
+			Category: "dead-guard",
+			Message: fmt.Sprintf("%s deferred in %s, which the program never starts as a goroutine: called synchronously it swallows panics its callers should see",
+				guard.Handler, guard.Func),
+		}
+		if pos := filePos(pass.Fset, guard.Pos); pos.IsValid() {
+			diagnostic.Related = []analysis.RelatedInformation{{Pos: pos, End: filePos(pass.Fset, guard.End), Message: "deferred here"}}
+		}
+		p.reportOnce(pass, diagnostic)
+	}
+}
+
+// startedFuncs returns the functions of the package a go statement with
+// callee fun may start: the named function or method, the callee of a
+// trampoline, and the values stored in the func-valued fields, parameters and
+// variables it calls, resolved as the goroutine check resolves them.
+func (p *Analyzer) startedFuncs(pass *analysis.Pass, fun ast.Expr, seen map[*types.Var]bool) []*types.Func {
+	if lit, ok := ast.Unparen(fun).(*ast.FuncLit); ok {
+		if call := trampolineCall(pass, lit.Body); call != nil {
+			return p.startedFuncs(pass, call.Fun, seen)
+		}
+		return nil
+	}
+
+	var holder *types.Var
+	switch e := unindex(fun).(type) {
+	case *ast.Ident:
+		holder, _ = pass.TypesInfo.Uses[e].(*types.Var)
+	case *ast.SelectorExpr:
+		if sel := pass.TypesInfo.Selections[e]; sel != nil && sel.Kind() == types.FieldVal {
+			holder, _ = sel.Obj().(*types.Var)
+		}
+	}
+	if holder == nil {
+		if fn := calleeFunc(fun, pass.TypesInfo); fn != nil {
+			return []*types.Func{fn}
+		}
+		return nil
+	}
+
+	holder = holder.Origin()
+	if seen[holder] {
+		return nil
+	}
+	seen[holder] = true
+	var funcs []*types.Func
+	for _, value := range p.holderValues(pass, holder) {
+		funcs = append(funcs, p.startedFuncs(pass, value, seen)...)
+	}
+	return funcs
+}
+
+// holderValues returns the function values the package stores in a
+// func-valued field, parameter or variable.
+func (p *Analyzer) holderValues(pass *analysis.Pass, holder *types.Var) []ast.Expr {
+	if holder.IsField() {
+		return fieldValues(pass, holder)
+	}
+	if fn, index := paramOf(pass, holder); fn != nil {
+		return callArguments(pass, fn, index)
+	}
+	return p.findAllFunctionAssignments(pass, holder)
+}
+
+// heldValues returns the identifiers of functions stored in unexported
+// fields, parameters and variables of the package that are only ever called
+// or assigned. Such values do not escape: startedFuncs finds them when a go
+// statement starts their holder.
+func (p *Analyzer) heldValues(pass *analysis.Pass) map[*ast.Ident]bool {
+	holders := map[*types.Var]bool{}
+	for _, file := range pass.Files {
+		var stack []ast.Node
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, n)
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			holder, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+			if !ok || holder.Pkg() != pass.Pkg {
+				return true
+			}
+			if _, isFunc := holder.Type().Underlying().(*types.Signature); !isFunc {
+				return true
+			}
+			holder = holder.Origin()
+			if _, known := holders[holder]; !known {
+				holders[holder] = !holder.Exported()
+			}
+			if !onlyCalledOrAssigned(ident, stack[:len(stack)-1]) {
+				holders[holder] = false
+			}
+			return true
+		})
+	}
+
+	held := map[*ast.Ident]bool{}
+	for holder, tracked := range holders {
+		if !tracked {
+			continue
+		}
+		for _, value := range p.holderValues(pass, holder) {
+			if ident := calleeIdent(value); ident != nil {
+				held[ident] = true
+			}
+		}
+	}
+	return held
+}
+
+// onlyCalledOrAssigned reports whether ident, with its parents on stack,
+// declares a holder, is assigned to, or is called.
+func onlyCalledOrAssigned(ident *ast.Ident, stack []ast.Node) bool {
+	var ref ast.Node = ident
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch parent := stack[i].(type) {
+		case *ast.ParenExpr:
+			ref = parent
+			continue
+		case *ast.SelectorExpr:
+			if parent.Sel == ident {
+				ref = parent
+				continue
+			}
+			return false
+		case *ast.CallExpr:
+			return parent.Fun == ref
+		case *ast.AssignStmt:
+			for _, lhs := range parent.Lhs {
+				if lhs == ref {
+					return true
+				}
+			}
+			return false
+		case *ast.KeyValueExpr:
+			return parent.Key == ref
+		case *ast.ValueSpec:
+			for _, name := range parent.Names {
+				if name == ident {
+					return true
+				}
+			}
+			return false
+		case *ast.Field:
+			return true
+		}
+		return false
+	}
+	return false
+}
+
+func isInterfaceMethod(fn *types.Func) bool {
+	sig, ok := fn.Type().(*types.Signature)
+	return ok && sig.Recv() != nil && types.IsInterface(sig.Recv().Type())
+}
+
+// handlerDefer returns the first top-level defer of the handler in body.
+func (p *Analyzer) handlerDefer(body *ast.BlockStmt, typeInfo *types.Info) *ast.DeferStmt {
+	for _, stmt := range body.List {
+		if deferStmt, ok := stmt.(*ast.DeferStmt); ok && p.matchTargetCall(deferStmt.Call.Fun, typeInfo) == nil {
+			return deferStmt
+		}
+	}
+	return nil
+}
+
+func calleeIdent(fun ast.Expr) *ast.Ident {
+	switch fun := ast.Unparen(fun).(type) {
+	case *ast.Ident:
+		return fun
+	case *ast.SelectorExpr:
+		return fun.Sel
+	case *ast.IndexExpr, *ast.IndexListExpr:
+		return calleeIdent(unindex(fun))
+	}
+	return nil
+}
+
+func calleeFunc(fun ast.Expr, typeInfo *types.Info) *types.Func {
+	ident := calleeIdent(fun)
+	if ident == nil {
+		return nil
+	}
+	fn, _ := typeInfo.Uses[ident].(*types.Func)
+	if fn != nil {
+		fn = fn.Origin()
+	}
+	return fn
+}
+
+// enclosingBody returns the body of the innermost function on stack.
+func enclosingBody(stack []ast.Node) *ast.BlockStmt {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncDecl:
+			return fn.Body
+		case *ast.FuncLit:
+			return fn.Body
+		}
+	}
+	return nil
+}
+
+func funcDeclName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return decl.Name.Name
+	}
+	return fmt.Sprintf("(%s).%s", types.ExprString(decl.Recv.List[0].Type), decl.Name.Name)
+}
+
+// isProgramEntry reports whether decl is main or init, whose handlers guard
+// the whole program rather than a goroutine.
+func isProgramEntry(decl *ast.FuncDecl) bool {
+	return decl.Recv == nil && (decl.Name.Name == "main" || decl.Name.Name == "init")
+}
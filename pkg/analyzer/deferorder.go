@@ -0,0 +1,110 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	deferOrderAllowlist = "allowlist"
+	deferOrderStrict    = "strict"
+)
+
+// deferOrder is the flag.Value for -defer-order.
+type deferOrder string
+
+func (d *deferOrder) String() string {
+	return string(*d)
+}
+
+func (d *deferOrder) Set(s string) error {
+	switch s {
+	case "", deferOrderAllowlist, deferOrderStrict:
+		*d = deferOrder(s)
+		return nil
+	}
+	return errors.Errorf("unknown defer order policy '%s', expected %s or %s", s, deferOrderAllowlist, deferOrderStrict)
+}
+
+// leadingHandlerDefer returns the handler among the defers that open stmts, so
+// that the defer order policy, not the structural check, decides about those
+// registered before it. Without a handler it returns the first defer.
+func (p *Analyzer) leadingHandlerDefer(pass *analysis.Pass, stmts []ast.Stmt, typeInfo *types.Info) *ast.DeferStmt {
+	first, _ := stmts[0].(*ast.DeferStmt)
+	for _, stmt := range stmts {
+		deferStatement, ok := stmt.(*ast.DeferStmt)
+		if !ok {
+			break
+		}
+		if p.checkHandlerDefer(pass, deferStatement, typeInfo) == nil {
+			return deferStatement
+		}
+	}
+	return first
+}
+
+// checkDeferOrder reports defers registered before the handler. Deferred
+// calls run in reverse order of registration, so these run after the handler
+// has returned and a panic inside them is not recovered. The allowlist policy
+// accepts the -defer-allow functions when their receiver and arguments cannot
+// panic; the strict policy accepts none.
+func (p *Analyzer) checkDeferOrder(pass *analysis.Pass, body *ast.BlockStmt, handler *ast.DeferStmt, typeInfo *types.Info) error {
+	if p.deferOrder == "" {
+		return nil
+	}
+	local := typeInfo == pass.TypesInfo
+	// allowed defers still evaluate their receiver and arguments before the
+	// handler is registered, and run after it has returned
+	checker := &panicChecker{info: typeInfo}
+	if local {
+		checker.files = pass.Files
+	}
+
+	var errs linterErrors
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			if node == handler || node.Pos() > handler.Pos() {
+				return true
+			}
+			call := types.ExprString(node.Call)
+			var message string
+			switch {
+			case p.deferOrder == deferOrderStrict:
+				message = fmt.Sprintf("the handler must be the first defer registered: defers run in reverse order, so "+
+					"defer %s runs after the handler has returned and a panic inside it is not recovered", call)
+			case p.matchCall(p.deferAllow, node.Call.Fun, typeInfo) != nil:
+				message = fmt.Sprintf("defer %s is registered before the handler, so it runs after the handler has returned "+
+					"and a panic inside it is not recovered; register it after the handler", call)
+			default:
+				err := checker.operands(node.Call)
+				if err == nil {
+					return true
+				}
+				message = fmt.Sprintf("defer %s is registered before the handler and may panic: %s", call, err.Error())
+			}
+			diagnostic := analysis.Diagnostic{Category: "policy", Message: message}
+			if local {
+				diagnostic.Pos = node.Pos()
+				diagnostic.End = node.End()
+			}
+			errs = append(errs, &guardError{diagnostic: diagnostic})
+		}
+		return true
+	})
+
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return errs
+	}
+}
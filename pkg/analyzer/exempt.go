@@ -0,0 +1,536 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// knownPanicFree lists standard library functions that cannot panic when
+// their receiver and pointer arguments are not nil and their arguments cannot
+// panic.
+var knownPanicFree = mustTargets(append(atomicPanicFree(),
+	"time.Now",
+	"time.Since",
+	"time.Sleep",
+	"runtime.Gosched",
+	"errors.New",
+)...)
+
+// atomicPanicFree lists the sync/atomic functions and methods on integers,
+// booleans and typed pointers. atomic.Value is left out: storing nil, or
+// values of different types, panics.
+func atomicPanicFree() []string {
+	var specs []string
+	for _, typ := range []string{"Int32", "Int64", "Uint32", "Uint64", "Uintptr"} {
+		for _, op := range []string{"Add", "And", "Or", "Load", "Store", "Swap", "CompareAndSwap"} {
+			specs = append(specs, "sync/atomic."+op+typ, fmt.Sprintf("(*sync/atomic.%s).%s", typ, op))
+		}
+	}
+	for _, op := range []string{"Load", "Store", "Swap", "CompareAndSwap"} {
+		specs = append(specs, "(*sync/atomic.Bool)."+op, "(*sync/atomic.Pointer)."+op)
+	}
+	return specs
+}
+
+func mustTargets(specs ...string) Targets {
+	var targets Targets
+	for _, spec := range specs {
+		if err := targets.Set(spec); err != nil {
+			panic(err)
+		}
+	}
+	return targets
+}
+
+// panicFreeProof proves goroutine bodies cannot panic. Callees are proven
+// from their own bodies when they belong to the analyzed package or, if the
+// driver reports one, its module; the explanation collects what was relied on.
+type panicFreeProof struct {
+	analyzer *Analyzer
+	pass     *analysis.Pass
+	// unsafeChannels holds the channel variables and fields that may be, or
+	// alias, a channel closed in the package.
+	unsafeChannels map[types.Object]bool
+	callees        map[*types.Func]error
+	proving        map[*types.Func]bool
+	reasons        []string
+}
+
+// provePanicFree explains why body cannot panic, or returns why that could
+// not be proven.
+func (p *Analyzer) provePanicFree(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) (string, error) {
+	proof := &panicFreeProof{
+		analyzer:       p,
+		pass:           pass,
+		unsafeChannels: unsafeChannels(pass),
+		callees:        map[*types.Func]error{},
+		proving:        map[*types.Func]bool{},
+	}
+	if err := proof.body(body, typeInfo); err != nil {
+		return "", err
+	}
+	if len(proof.reasons) == 0 {
+		return "it only evaluates expressions that cannot panic", nil
+	}
+	return strings.Join(proof.reasons, "; "), nil
+}
+
+// unsafeChannels collects the channel variables and fields of the analyzed
+// package that may be closed, or may alias a channel that is: those passed to
+// close(), those whose value escapes anywhere but a send, a receive, a range,
+// len or cap, and those assigned anything but make(chan) or declared as
+// parameters and range or type switch variables.
+func unsafeChannels(pass *analysis.Pass) map[types.Object]bool {
+	unsafe := map[types.Object]bool{}
+	fromMake := func(obj types.Object, value ast.Expr) {
+		if call, ok := ast.Unparen(value).(*ast.CallExpr); ok {
+			if ident, ok := ast.Unparen(call.Fun).(*ast.Ident); ok && ident.Name == "make" {
+				return
+			}
+		}
+		unsafe[obj] = true
+	}
+
+	for _, file := range pass.Files {
+		var stack []ast.Node
+		ast.Inspect(file, func(n ast.Node) bool {
+			if n == nil {
+				stack = stack[:len(stack)-1]
+				return true
+			}
+			stack = append(stack, n)
+			if lit, ok := n.(*ast.CompositeLit); ok {
+				// positional fields never name the field
+				if st, ok := typeUnderlying(pass.TypesInfo.TypeOf(lit)).(*types.Struct); ok {
+					for i, elt := range lit.Elts {
+						if _, keyed := elt.(*ast.KeyValueExpr); !keyed && i < st.NumFields() {
+							if _, isChan := st.Field(i).Type().Underlying().(*types.Chan); isChan {
+								fromMake(st.Field(i).Origin(), elt)
+							}
+						}
+					}
+				}
+			}
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+			if !ok || obj.Pkg() != pass.Pkg {
+				return true
+			}
+			obj = obj.Origin()
+			if _, isChan := obj.Type().Underlying().(*types.Chan); !isChan {
+				return true
+			}
+
+			// ref is the expression naming the channel: the identifier, or the
+			// selector it is the field of
+			var ref ast.Expr = ident
+			parents := stack[:len(stack)-1]
+			if len(parents) > 0 {
+				if sel, ok := parents[len(parents)-1].(*ast.SelectorExpr); ok && sel.Sel == ident {
+					ref = sel
+					parents = parents[:len(parents)-1]
+				}
+			}
+			for len(parents) > 0 {
+				if _, ok := parents[len(parents)-1].(*ast.ParenExpr); !ok {
+					break
+				}
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) == 0 {
+				return true
+			}
+
+			switch parent := parents[len(parents)-1].(type) {
+			case *ast.SendStmt:
+				if parent.Chan != ref && ast.Unparen(parent.Chan) != ref {
+					unsafe[obj] = true
+				}
+			case *ast.UnaryExpr:
+				if parent.Op != token.ARROW {
+					unsafe[obj] = true
+				}
+			case *ast.RangeStmt:
+				if parent.X != ref && ast.Unparen(parent.X) != ref {
+					unsafe[obj] = true
+				}
+			case *ast.CallExpr:
+				builtin, ok := ast.Unparen(parent.Fun).(*ast.Ident)
+				if !ok || (builtin.Name != "len" && builtin.Name != "cap") {
+					unsafe[obj] = true
+				}
+			case *ast.AssignStmt:
+				assigned := false
+				for i, lhs := range parent.Lhs {
+					if lhs == ref || ast.Unparen(lhs) == ref {
+						assigned = true
+						if len(parent.Lhs) != len(parent.Rhs) {
+							unsafe[obj] = true
+						} else {
+							fromMake(obj, parent.Rhs[i])
+						}
+					}
+				}
+				if !assigned {
+					unsafe[obj] = true
+				}
+			case *ast.ValueSpec:
+				for i, name := range parent.Names {
+					if name == ident && i < len(parent.Values) {
+						fromMake(obj, parent.Values[i])
+					}
+				}
+				if len(parent.Values) > 0 && len(parent.Values) != len(parent.Names) {
+					unsafe[obj] = true
+				}
+			case *ast.KeyValueExpr:
+				if parent.Key == ref {
+					fromMake(obj, parent.Value)
+				} else {
+					unsafe[obj] = true
+				}
+			case *ast.Field:
+				if !obj.IsField() {
+					unsafe[obj] = true
+				}
+			default:
+				unsafe[obj] = true
+			}
+			return true
+		})
+	}
+	return unsafe
+}
+
+func (v *panicFreeProof) reason(format string, args ...any) {
+	reason := fmt.Sprintf(format, args...)
+	for _, existing := range v.reasons {
+		if existing == reason {
+			return
+		}
+	}
+	v.reasons = append(v.reasons, reason)
+}
+
+func (v *panicFreeProof) body(body *ast.BlockStmt, typeInfo *types.Info) error {
+	checker := &panicChecker{info: typeInfo}
+	if typeInfo == v.pass.TypesInfo {
+		checker.files = v.pass.Files
+	}
+	checker.call = func(call *ast.CallExpr) error {
+		return v.call(checker, call)
+	}
+	return v.stmts(checker, body.List)
+}
+
+func (v *panicFreeProof) stmts(c *panicChecker, stmts []ast.Stmt) error {
+	for _, stmt := range stmts {
+		if err := v.stmt(c, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *panicFreeProof) stmt(c *panicChecker, stmt ast.Stmt) error {
+	switch s := stmt.(type) {
+	case nil, *ast.EmptyStmt, *ast.BranchStmt:
+		return nil
+	case *ast.ExprStmt:
+		return c.expr(s.X)
+	case *ast.SendStmt:
+		return v.send(c, s)
+	case *ast.IncDecStmt:
+		return v.assignTo(c, s.X)
+	case *ast.AssignStmt:
+		return v.assign(c, s)
+	case *ast.DeclStmt:
+		if decl, ok := s.Decl.(*ast.GenDecl); ok {
+			for _, spec := range decl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					for _, value := range valueSpec.Values {
+						if err := c.expr(value); err != nil {
+							return err
+						}
+					}
+				}
+			}
+		}
+		return nil
+	case *ast.ReturnStmt:
+		for _, result := range s.Results {
+			if err := c.expr(result); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.BlockStmt:
+		return v.stmts(c, s.List)
+	case *ast.LabeledStmt:
+		return v.stmt(c, s.Stmt)
+	case *ast.IfStmt:
+		if err := v.stmt(c, s.Init); err != nil {
+			return err
+		}
+		if err := c.expr(s.Cond); err != nil {
+			return err
+		}
+		if err := v.stmts(c, s.Body.List); err != nil {
+			return err
+		}
+		return v.stmt(c, s.Else)
+	case *ast.ForStmt:
+		if err := v.stmt(c, s.Init); err != nil {
+			return err
+		}
+		if err := c.expr(s.Cond); err != nil {
+			return err
+		}
+		if err := v.stmt(c, s.Post); err != nil {
+			return err
+		}
+		return v.stmts(c, s.Body.List)
+	case *ast.RangeStmt:
+		return v.rangeStmt(c, s)
+	case *ast.SwitchStmt:
+		if err := v.stmt(c, s.Init); err != nil {
+			return err
+		}
+		if err := c.expr(s.Tag); err != nil {
+			return err
+		}
+		if s.Tag != nil {
+			// each case is compared with the tag
+			for _, clause := range s.Body.List {
+				for _, expr := range clause.(*ast.CaseClause).List {
+					if c.comparisonMayPanic(s.Tag, expr) {
+						return errors.Errorf("switch on %s may panic on incomparable dynamic types", types.ExprString(s.Tag))
+					}
+				}
+			}
+		}
+		return v.clauses(c, s.Body)
+	case *ast.TypeSwitchStmt:
+		if err := v.stmt(c, s.Init); err != nil {
+			return err
+		}
+		var guard ast.Expr
+		switch assign := s.Assign.(type) {
+		case *ast.ExprStmt:
+			guard = assign.X
+		case *ast.AssignStmt:
+			guard = assign.Rhs[0]
+		}
+		if assert, ok := guard.(*ast.TypeAssertExpr); ok {
+			if err := c.expr(assert.X); err != nil {
+				return err
+			}
+		}
+		return v.clauses(c, s.Body)
+	case *ast.SelectStmt:
+		return v.clauses(c, s.Body)
+	case *ast.DeferStmt:
+		return c.expr(s.Call)
+	case *ast.GoStmt:
+		if _, ok := s.Call.Fun.(*ast.FuncLit); !ok {
+			if err := c.expr(s.Call.Fun); err != nil {
+				return err
+			}
+		}
+		return c.args(s.Call)
+	default:
+		return errors.Errorf("cannot prove %T is panic-free", stmt)
+	}
+}
+
+func (v *panicFreeProof) clauses(c *panicChecker, body *ast.BlockStmt) error {
+	for _, clause := range body.List {
+		switch clause := clause.(type) {
+		case *ast.CaseClause:
+			for _, expr := range clause.List {
+				if err := c.expr(expr); err != nil {
+					return err
+				}
+			}
+			if err := v.stmts(c, clause.Body); err != nil {
+				return err
+			}
+		case *ast.CommClause:
+			if err := v.stmt(c, clause.Comm); err != nil {
+				return err
+			}
+			if err := v.stmts(c, clause.Body); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// send allows sends only on channels that provably are not nil and can neither
+// be closed in the package nor alias a channel that is.
+func (v *panicFreeProof) send(c *panicChecker, s *ast.SendStmt) error {
+	if err := c.expr(s.Value); err != nil {
+		return err
+	}
+
+	var obj types.Object
+	switch ch := ast.Unparen(s.Chan).(type) {
+	case *ast.Ident:
+		obj = c.info.ObjectOf(ch)
+	case *ast.SelectorExpr:
+		if err := c.expr(ch.X); err != nil {
+			return err
+		}
+		obj = c.info.ObjectOf(ch.Sel)
+	}
+	if field, ok := obj.(*types.Var); ok {
+		obj = field.Origin()
+	}
+	name := types.ExprString(s.Chan)
+	if obj == nil || v.unsafeChannels[obj] || c.info != v.pass.TypesInfo {
+		return errors.Errorf("send on %s may panic if it is closed", name)
+	}
+	if !c.nonNil(s.Chan) {
+		return errors.Errorf("channel %s may be nil", name)
+	}
+	v.reason("sends on %s, which is never closed", name)
+	return nil
+}
+
+func (v *panicFreeProof) assign(c *panicChecker, s *ast.AssignStmt) error {
+	if len(s.Lhs) == 2 && len(s.Rhs) == 1 {
+		// comma-ok forms: type assertions cannot panic here
+		if assert, ok := ast.Unparen(s.Rhs[0]).(*ast.TypeAssertExpr); ok {
+			if err := c.expr(assert.X); err != nil {
+				return err
+			}
+			return v.assignTo(c, s.Lhs...)
+		}
+	}
+
+	for _, rhs := range s.Rhs {
+		if err := c.expr(rhs); err != nil {
+			return err
+		}
+	}
+
+	switch s.Tok {
+	case token.QUO_ASSIGN, token.REM_ASSIGN:
+		if divisionMayPanic(c.info.TypeOf(s.Lhs[0]), c.info.Types[s.Rhs[0]].Value) {
+			return errors.Errorf("division by %s may panic", types.ExprString(s.Rhs[0]))
+		}
+	case token.SHL_ASSIGN, token.SHR_ASSIGN:
+		if shiftMayPanic(c.info.TypeOf(s.Rhs[0]), c.info.Types[s.Rhs[0]].Value) {
+			return errors.Errorf("shift by %s may panic", types.ExprString(s.Rhs[0]))
+		}
+	}
+	return v.assignTo(c, s.Lhs...)
+}
+
+// assignTo checks the targets of an assignment: writes to possibly nil maps
+// and through possibly nil pointers panic.
+func (v *panicFreeProof) assignTo(c *panicChecker, targets ...ast.Expr) error {
+	for _, target := range targets {
+		if err := c.assignTarget(target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *panicFreeProof) rangeStmt(c *panicChecker, s *ast.RangeStmt) error {
+	if err := c.expr(s.X); err != nil {
+		return err
+	}
+	if _, isChan := c.info.TypeOf(s.X).Underlying().(*types.Chan); isChan && !c.nonNil(s.X) {
+		return errors.Errorf("channel %s may be nil", types.ExprString(s.X))
+	}
+	if s.Tok == token.ASSIGN {
+		if err := v.assignTo(c, s.Key, s.Value); err != nil {
+			return err
+		}
+	}
+	return v.stmts(c, s.Body.List)
+}
+
+// call accepts calls to known panic-free functions and to functions whose
+// bodies are proven panic-free, given a receiver and arguments that cannot
+// panic.
+func (v *panicFreeProof) call(c *panicChecker, call *ast.CallExpr) error {
+	var fn *types.Func
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		fn, _ = c.info.Uses[fun].(*types.Func)
+	case *ast.SelectorExpr:
+		fn, _ = c.info.Uses[fun.Sel].(*types.Func)
+	}
+	if fn == nil {
+		return errors.Errorf("call of function value %s may panic", types.ExprString(call.Fun))
+	}
+	if err := c.operands(call); err != nil {
+		return err
+	}
+
+	if v.analyzer.matchFuncObject(knownPanicFree, fn, false) == nil {
+		for _, arg := range call.Args {
+			if _, isPointer := c.info.TypeOf(arg).Underlying().(*types.Pointer); isPointer && !c.nonNil(arg) {
+				return errors.Errorf("argument %s to %s may be nil", types.ExprString(arg), fn.FullName())
+			}
+		}
+		v.reason("calls %s, which is known not to panic", fn.FullName())
+		return nil
+	}
+	if err := v.callee(fn); err != nil {
+		return errors.Wrapf(err, "call to %s", fn.FullName())
+	}
+	v.reason("calls %s, which is proven not to panic", fn.FullName())
+	return nil
+}
+
+func (v *panicFreeProof) callee(fn *types.Func) error {
+	if err, done := v.callees[fn]; done {
+		return err
+	}
+	if v.proving[fn] {
+		return errors.New("recursion may overflow the stack")
+	}
+
+	var body *ast.BlockStmt
+	var typeInfo *types.Info
+	if decl := findFuncDecl(v.pass, fn); decl != nil {
+		body, typeInfo = decl.Body, v.pass.TypesInfo
+	} else if v.inModule(fn) {
+		var err error
+		if body, typeInfo, err = v.analyzer.findFuncBodyInObjectPackage(fn); err != nil {
+			return errors.Wrap(err, "cannot load the function body")
+		}
+	}
+	if body == nil {
+		return errors.New("it may panic")
+	}
+
+	v.proving[fn] = true
+	err := v.body(body, typeInfo)
+	delete(v.proving, fn)
+	v.callees[fn] = err
+	return err
+}
+
+func (v *panicFreeProof) inModule(fn *types.Func) bool {
+	module := v.pass.Module
+	if module == nil || module.Path == "" || fn.Pkg() == nil {
+		return false
+	}
+	path := fn.Pkg().Path()
+	return path == module.Path || strings.HasPrefix(path, module.Path+"/")
+}
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkFieldGoroutine verifies every function the package can store in a
+// func-valued struct field started as a goroutine, as in go s.onEvent().
+// Fields of other packages, and fields never assigned here, are not reported.
+// A field reached again while its own values are checked adds nothing new.
+func (p *Analyzer) checkFieldGoroutine(pass *analysis.Pass, field *types.Var, callPos token.Pos) error {
+	field = field.Origin()
+	if field.Pkg() != pass.Pkg {
+		p.logger.Printf("cannot verify field of another package field=%s pkg=%s", field.Name(), field.Pkg().Path())
+		return nil
+	}
+	if _, resolving := p.resolvingFields.LoadOrStore(field, struct{}{}); resolving {
+		return nil
+	}
+	defer p.resolvingFields.Delete(field)
+
+	values := p.expandParams(pass, fieldValues(pass, field))
+	if len(values) == 0 {
+		p.logger.Printf("no function assigned to field field=%s", field.Name())
+		return nil
+	}
+	for _, value := range values {
+		if sel, ok := ast.Unparen(value).(*ast.SelectorExpr); ok {
+			if selection := pass.TypesInfo.Selections[sel]; selection != nil && sameField(selection.Obj(), field) {
+				// copied from the same field of another value
+				continue
+			}
+		}
+		if err := p.checkFuncValue(pass, value, callPos); err != nil {
+			return errors.Wrapf(err, "field %s set to %s", field.Name(), types.ExprString(value))
+		}
+	}
+	return nil
+}
+
+// fieldValues collects the expressions assigned to field in the package:
+// composite literal elements, keyed or positional, and field assignments.
+func fieldValues(pass *analysis.Pass, field *types.Var) []ast.Expr {
+	var values []ast.Expr
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.CompositeLit:
+				st, ok := typeUnderlying(pass.TypesInfo.TypeOf(node)).(*types.Struct)
+				if !ok {
+					return true
+				}
+				for i, elt := range node.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok && sameField(pass.TypesInfo.Uses[key], field) {
+							values = append(values, kv.Value)
+						}
+						continue
+					}
+					if i < st.NumFields() && sameField(st.Field(i), field) {
+						values = append(values, elt)
+					}
+				}
+			case *ast.AssignStmt:
+				if len(node.Lhs) != len(node.Rhs) {
+					return true
+				}
+				for i, lhs := range node.Lhs {
+					sel, ok := ast.Unparen(lhs).(*ast.SelectorExpr)
+					if !ok {
+						continue
+					}
+					if selection := pass.TypesInfo.Selections[sel]; selection != nil && sameField(selection.Obj(), field) {
+						values = append(values, node.Rhs[i])
+					}
+				}
+			}
+			return true
+		})
+	}
+	return values
+}
+
+// typeUnderlying returns the underlying type of t, looking through a pointer
+// as composite literals of &T{} do.
+func typeUnderlying(t types.Type) types.Type {
+	if t == nil {
+		return nil
+	}
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		return ptr.Elem().Underlying()
+	}
+	return t.Underlying()
+}
+
+func sameField(obj types.Object, field *types.Var) bool {
+	v, ok := obj.(*types.Var)
+	return ok && v.IsField() && v.Origin() == field
+}
+
+// expandParams replaces values that are parameters of functions declared in
+// the package, such as the handler passed to a constructor, with the
+// arguments given at the function's call sites.
+func (p *Analyzer) expandParams(pass *analysis.Pass, values []ast.Expr) []ast.Expr {
+	seen := map[*types.Var]bool{}
+	var expanded []ast.Expr
+	for len(values) > 0 {
+		value := values[0]
+		values = values[1:]
+
+		if ident, ok := ast.Unparen(value).(*ast.Ident); ok {
+			if param, ok := pass.TypesInfo.Uses[ident].(*types.Var); ok {
+				if fn, index := paramOf(pass, param); fn != nil {
+					if !seen[param] {
+						seen[param] = true
+						values = append(values, callArguments(pass, fn, index)...)
+					}
+					continue
+				}
+			}
+		}
+		expanded = append(expanded, value)
+	}
+	return expanded
+}
+
+// paramOf returns the function declared in the package that has param as a
+// parameter, and its index; variadic parameters are not tracked.
+func paramOf(pass *analysis.Pass, param *types.Var) (*types.Func, int) {
+	for _, file := range pass.Files {
+		for _, d := range file.Decls {
+			decl, ok := d.(*ast.FuncDecl)
+			if !ok || decl.Type.Params == nil {
+				continue
+			}
+			index := 0
+			for _, field := range decl.Type.Params.List {
+				if _, variadic := field.Type.(*ast.Ellipsis); variadic {
+					break
+				}
+				for _, name := range field.Names {
+					if pass.TypesInfo.Defs[name] == param {
+						fn, _ := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+						return fn, index
+					}
+					index++
+				}
+				if len(field.Names) == 0 {
+					index++
+				}
+			}
+		}
+	}
+	return nil, -1
+}
+
+// callArguments returns the argument at index of every call to fn in the
+// package.
+func callArguments(pass *analysis.Pass, fn *types.Func, index int) []ast.Expr {
+	var args []ast.Expr
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || call.Ellipsis.IsValid() || index >= len(call.Args) {
+				return true
+			}
+			if calleeFunc(call.Fun, pass.TypesInfo) == fn {
+				args = append(args, call.Args[index])
+			}
+			return true
+		})
+	}
+	return args
+}
+
+// checkFuncValue verifies a function value that may be started as a
+// goroutine: a literal, or a named function, method value or variable
+// resolved like the callee of a go statement. nil is not a function to check.
+func (p *Analyzer) checkFuncValue(pass *analysis.Pass, value ast.Expr, callPos token.Pos) error {
+	if pass.TypesInfo.Types[value].IsNil() {
+		return nil
+	}
+	switch v := unindex(value).(type) {
+	case *ast.FuncLit:
+		return p.checkGoroutine(pass, v.Body, pass.TypesInfo)
+	case *ast.Ident, *ast.SelectorExpr:
+		return p.checkGoroutineDefinition(pass, v, callPos)
+	}
+	p.logger.Printf("cannot verify function value value=%s", types.ExprString(value))
+	return nil
+}
@@ -0,0 +1,326 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ssa"
+)
+
+// ssaFunction returns the SSA form of a goroutine body from the analyzed
+// package, building the package on first use so that runs without -ssa never
+// pay for it. Bodies loaded from other packages have none and keep the
+// structural check.
+func (p *Analyzer) ssaFunction(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) *ssa.Function {
+	if typeInfo != pass.TypesInfo {
+		return nil
+	}
+	if functions, ok := p.ssaFunctions.Load(pass); ok {
+		return functions.(map[*ast.BlockStmt]*ssa.Function)[body]
+	}
+
+	functions, err := buildSSA(pass)
+	if err != nil {
+		p.logger.Printf("cannot build SSA, keeping the structural check pkg=%s reason=%s", pass.Pkg.Path(), err.Error())
+	}
+	p.ssaFunctions.Store(pass, functions)
+	return functions[body]
+}
+
+// buildSSA builds the analyzed package the way the buildssa pass does and
+// indexes its source functions, including literals, by body. The SSA builder
+// panics on syntax it does not support; that is returned as an error.
+func buildSSA(pass *analysis.Pass) (functions map[*ast.BlockStmt]*ssa.Function, err error) {
+	functions = map[*ast.BlockStmt]*ssa.Function{}
+	defer func() {
+		if r := recover(); r != nil {
+			functions = map[*ast.BlockStmt]*ssa.Function{}
+			err = errors.Errorf("%v", r)
+		}
+	}()
+
+	prog := ssa.NewProgram(pass.Fset, ssa.BuilderMode(0))
+	for _, imp := range pass.Pkg.Imports() {
+		prog.CreatePackage(imp, nil, nil, true)
+	}
+	ssapkg := prog.CreatePackage(pass.Pkg, pass.Files, pass.TypesInfo, false)
+	ssapkg.Build()
+
+	var add func(fn *ssa.Function)
+	add = func(fn *ssa.Function) {
+		if fn == nil {
+			return
+		}
+		switch syntax := fn.Syntax().(type) {
+		case *ast.FuncDecl:
+			functions[syntax.Body] = fn
+		case *ast.FuncLit:
+			functions[syntax.Body] = fn
+		}
+		for _, anon := range fn.AnonFuncs {
+			add(anon)
+		}
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				if fn, ok := pass.TypesInfo.Defs[fd.Name].(*types.Func); ok {
+					add(prog.FuncValue(fn))
+				}
+			}
+		}
+	}
+	// function literals in package-level variables belong to the package initializer
+	add(ssapkg.Func("init"))
+
+	return functions, nil
+}
+
+// checkGoroutineFlow verifies that on every path through fn the handler is
+// deferred before any instruction that can panic. Paths that return without
+// reaching such an instruction need no handler.
+func (p *Analyzer) checkGoroutineFlow(pass *analysis.Pass, fn *ssa.Function, body *ast.BlockStmt, typeInfo *types.Info) error {
+	handlers := map[token.Pos]*ast.DeferStmt{}
+	var order []*ast.DeferStmt
+	var mismatch error
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.DeferStmt:
+			if err := p.checkHandlerDefer(pass, node, typeInfo); err != nil {
+				if mismatch == nil {
+					mismatch = err
+				}
+				return true
+			}
+			handlers[node.Defer] = node
+			order = append(order, node)
+		}
+		return true
+	})
+	if len(handlers) == 0 {
+		if mismatch != nil {
+			return mismatch
+		}
+		return errors.New("no defer statement")
+	}
+	if len(fn.Blocks) == 0 {
+		return nil
+	}
+
+	guarded := map[*ast.DeferStmt]bool{}
+	seen := map[*ssa.BasicBlock]bool{}
+	var walk func(block *ssa.BasicBlock) error
+	walk = func(block *ssa.BasicBlock) error {
+		if seen[block] {
+			return nil
+		}
+		seen[block] = true
+
+		for _, instr := range block.Instrs {
+			if d, ok := instr.(*ssa.Defer); ok {
+				if handler := handlers[d.Pos()]; handler != nil {
+					guarded[handler] = true
+					return nil
+				}
+			}
+			if operation := panickingOperation(instr); operation != "" {
+				return errors.Errorf("%s may panic before the handler is deferred", operation)
+			}
+		}
+		for _, succ := range block.Succs {
+			if err := walk(succ); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(fn.Blocks[0]); err != nil {
+		return err
+	}
+
+	var first *ast.DeferStmt
+	for _, handler := range order {
+		if guarded[handler] {
+			p.checkLabel(pass, handler, typeInfo)
+			if first == nil {
+				first = handler
+			}
+		}
+	}
+	if first == nil {
+		return nil
+	}
+	return p.checkDeferOrder(pass, body, first, typeInfo)
+}
+
+// panickingOperation describes instr if it can panic: calls, index and slice
+// operations, dereferences of pointers that may be nil, conversions, type
+// assertions, map writes, channel sends, integer division and shifts.
+func panickingOperation(instr ssa.Instruction) string {
+	switch instr := instr.(type) {
+	case *ssa.Call:
+		return callPanics(instr.Common())
+	case *ssa.Go:
+		if instr.Call.StaticCallee() == nil {
+			return "go statement"
+		}
+	case *ssa.Panic:
+		return "panic"
+	case *ssa.Send:
+		return "channel send"
+	case *ssa.Select:
+		for _, state := range instr.States {
+			if state.Dir == types.SendOnly {
+				return "select with a send"
+			}
+		}
+	case *ssa.MapUpdate:
+		return "map update"
+	case *ssa.Index:
+		if !isConstant(instr.Index) {
+			return "index expression"
+		}
+	case *ssa.IndexAddr:
+		if _, isSlice := instr.X.Type().Underlying().(*types.Slice); isSlice || !isConstant(instr.Index) || !nonNilPointer(instr.X) {
+			return "index expression"
+		}
+	case *ssa.Lookup:
+		m, isMap := instr.X.Type().Underlying().(*types.Map)
+		if !isMap {
+			return "index expression"
+		}
+		if mapKeyMayPanic(m.Key()) && !isNilConst(instr.Index) {
+			return "map lookup with an interface key"
+		}
+	case *ssa.Slice:
+		if instr.Low != nil || instr.High != nil || instr.Max != nil {
+			return "slice expression"
+		}
+		if _, isPointer := instr.X.Type().Underlying().(*types.Pointer); isPointer && !nonNilPointer(instr.X) {
+			return "slice expression"
+		}
+	case *ssa.FieldAddr:
+		if !nonNilPointer(instr.X) {
+			return "dereference of " + valueName(instr.X)
+		}
+	case *ssa.UnOp:
+		if instr.Op == token.MUL && !nonNilPointer(instr.X) {
+			return "dereference of " + valueName(instr.X)
+		}
+	case *ssa.Store:
+		if !nonNilPointer(instr.Addr) {
+			return "store through " + valueName(instr.Addr)
+		}
+	case *ssa.BinOp:
+		return binOpPanics(instr)
+	case *ssa.TypeAssert:
+		if !instr.CommaOk {
+			return "type assertion"
+		}
+	case *ssa.SliceToArrayPointer, *ssa.MultiConvert:
+		return "conversion"
+	case *ssa.MakeSlice:
+		if makeMayPanic(constValue(instr.Len), constValue(instr.Cap)) {
+			return "make"
+		}
+	case *ssa.MakeChan:
+		if makeMayPanic(constValue(instr.Size)) {
+			return "make"
+		}
+	case *ssa.MakeMap:
+		if instr.Reserve != nil && makeMayPanic(constValue(instr.Reserve)) {
+			return "make"
+		}
+	}
+	return ""
+}
+
+func callPanics(call *ssa.CallCommon) string {
+	if builtin, ok := call.Value.(*ssa.Builtin); ok {
+		switch builtin.Name() {
+		case "len", "cap", "append", "copy", "print", "println", "min", "max",
+			"real", "imag", "complex", "delete", "clear", "recover":
+			return ""
+		}
+		return "call to " + builtin.Name()
+	}
+	if call.IsInvoke() {
+		return "call to " + call.Method.FullName()
+	}
+	if callee := call.StaticCallee(); callee != nil {
+		return "call to " + callee.String()
+	}
+	return "call of function value " + valueName(call.Value)
+}
+
+func binOpPanics(instr *ssa.BinOp) string {
+	switch instr.Op {
+	case token.QUO, token.REM:
+		if divisionMayPanic(instr.Type(), constValue(instr.Y)) {
+			return "integer division"
+		}
+	case token.SHL, token.SHR:
+		if shiftMayPanic(instr.Y.Type(), constValue(instr.Y)) {
+			return "shift"
+		}
+	case token.EQL, token.NEQ:
+		if comparisonMayPanic(instr.X.Type(), instr.Y.Type(), isNilConst(instr.X) || isNilConst(instr.Y)) {
+			return "comparison of interface values"
+		}
+	}
+	return ""
+}
+
+func isConstant(v ssa.Value) bool {
+	_, ok := v.(*ssa.Const)
+	return ok
+}
+
+// constValue returns the value of a constant, or nil for any other value.
+func constValue(v ssa.Value) constant.Value {
+	if c, ok := v.(*ssa.Const); ok {
+		return c.Value
+	}
+	return nil
+}
+
+func isNilConst(v ssa.Value) bool {
+	c, ok := v.(*ssa.Const)
+	return ok && c.IsNil()
+}
+
+// nonNilPointer reports whether v points to a local allocation, a captured or
+// global variable, or a field or array element of one.
+func nonNilPointer(v ssa.Value) bool {
+	switch v := v.(type) {
+	case *ssa.Alloc, *ssa.FreeVar, *ssa.Global:
+		return true
+	case *ssa.FieldAddr:
+		return nonNilPointer(v.X)
+	case *ssa.IndexAddr:
+		_, isSlice := v.X.Type().Underlying().(*types.Slice)
+		return !isSlice && isConstant(v.Index) && nonNilPointer(v.X)
+	}
+	return false
+}
+
+// valueName names v for diagnostics: source-level names for parameters and
+// variables, a generic description for temporaries.
+func valueName(v ssa.Value) string {
+	switch v := v.(type) {
+	case *ssa.Parameter, *ssa.FreeVar, *ssa.Global, *ssa.Function:
+		return v.Name()
+	case *ssa.Alloc:
+		if v.Comment != "" {
+			return v.Comment
+		}
+	}
+	return "a computed value"
+}
@@ -0,0 +1,18 @@
+package analyzer
+
+import "go/ast"
+
+// unindex strips explicit instantiations such as process[int] or
+// Pair[K, V] from a function expression, along with parentheses.
+func unindex(expr ast.Expr) ast.Expr {
+	for {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		default:
+			return e
+		}
+	}
+}
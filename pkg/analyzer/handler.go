@@ -0,0 +1,414 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/status-im/goroutine-defer-guard/pkg/utils"
+)
+
+// handlerFact records why a configured handler cannot stop a panic, so that
+// packages deferring it from elsewhere can report the problems without
+// loading the body of the handler themselves.
+type handlerFact struct {
+	Problems []string
+}
+
+func (*handlerFact) AFact() {}
+
+func (f *handlerFact) String() string {
+	return fmt.Sprintf("handler problems: %s", strings.Join(f.Problems, "; "))
+}
+
+// verifyTargets checks that every configured handler exists and, with
+// -verify-handlers, that it calls recover() directly; otherwise it cannot stop
+// a panic and every goroutine the analyzer approves is still unprotected. A
+// missing handler is reported only by the package responsible for it, at its
+// package clause, so the finding does not depend on which package is
+// analyzed first. Body problems are reported at the handler declaration and
+// exported as a fact, which packages declaring goroutines report at pos, their
+// first go statement.
+func (p *Analyzer) verifyTargets(pass *analysis.Pass, pos token.Pos) {
+	verified := map[*types.Func]bool{}
+	for _, target := range p.targets {
+		if target.pattern != nil {
+			continue
+		}
+		imported := findImportedPackage(pass.Pkg, target.PackagePath)
+		if imported == nil && !closestPackage(pass.Pkg, target.PackagePath) {
+			continue
+		}
+
+		fn, err := p.resolveTarget(pass.Pkg, target)
+		if err != nil {
+			if imported == nil || imported == pass.Pkg {
+				pass.Report(analysis.Diagnostic{
+					Pos:      pass.Files[0].Package,
+					Category: "configuration",
+					Message:  fmt.Sprintf("configured handler %s does not exist: %s", target, err.Error()),
+				})
+			}
+			continue
+		}
+		if fn == nil || !p.verifyHandlers || verified[fn] {
+			continue
+		}
+		verified[fn] = true
+
+		if fn.Pkg() == pass.Pkg {
+			p.verifyTarget(pass, target, fn)
+			continue
+		}
+		var fact handlerFact
+		if pos.IsValid() && pass.ImportObjectFact(fn, &fact) {
+			for _, problem := range fact.Problems {
+				pass.Report(analysis.Diagnostic{Pos: pos, Category: "configuration", Message: problem})
+			}
+		}
+	}
+}
+
+// closestPackage reports whether pkg is the best match for a package path
+// missing from its import graph, which makes it responsible for reporting a
+// misspelled target path.
+func closestPackage(pkg *types.Package, path string) bool {
+	closest := utils.Closest(path, importedPaths(pkg), 1)
+	return len(closest) == 1 && closest[0] == pkg.Path()
+}
+
+// verifyTarget checks the body of a handler declared in the analyzed package
+// and exports the problems it finds as a handlerFact.
+func (p *Analyzer) verifyTarget(pass *analysis.Pass, target Target, fn *types.Func) {
+	decl := findFuncDecl(pass, fn)
+	if decl == nil {
+		return
+	}
+
+	bodies := []*ast.BlockStmt{decl.Body}
+	if target.Factory {
+		bodies = returnedFuncLits(decl.Body)
+		if len(bodies) == 0 {
+			p.logger.Printf("cannot verify handler factory function=%s reason=no function literal returned", fn.FullName())
+			return
+		}
+	}
+
+	var fact handlerFact
+	report := func(pos token.Pos, message string) {
+		pass.Report(analysis.Diagnostic{Pos: pos, Category: "configuration", Message: message})
+		fact.Problems = append(fact.Problems, message)
+	}
+	for _, body := range bodies {
+		calls := directRecoverCalls(body, pass.TypesInfo)
+		if len(calls) == 0 {
+			message := "configured handler %s never calls recover(), so it cannot stop a panic"
+			if callsRecoverIndirectly(body, pass.TypesInfo) {
+				message = "configured handler %s calls recover() only from a nested function, where it always returns nil"
+			}
+			report(decl.Name.Pos(), fmt.Sprintf(message, target))
+			continue
+		}
+
+		for _, call := range calls {
+			if recoveredValueDiscarded(body, call, pass.TypesInfo) {
+				report(call.Pos(), fmt.Sprintf("configured handler %s discards the recovered value without reporting it", target))
+			}
+		}
+	}
+	// unqualified targets resolve in each package separately, so only
+	// qualified ones can be deferred from another package
+	if len(fact.Problems) > 0 && target.PackagePath != "" {
+		pass.ExportObjectFact(fn, &fact)
+	}
+}
+
+// resolveTarget finds a function or concrete method target, in the import
+// graph of pkg or by loading its package, and explains what is missing if it
+// does not exist. Patterns, interface methods and unqualified names, which may
+// be declared per package, resolve to nil without an error when they have no
+// single body in pkg.
+func (p *Analyzer) resolveTarget(pkg *types.Package, target Target) (*types.Func, error) {
+	if target.pattern != nil {
+		return nil, nil
+	}
+
+	targetPkg := findImportedPackage(pkg, target.PackagePath)
+	if targetPkg == nil {
+		var err error
+		if targetPkg, err = p.loadTargetPackage(target.PackagePath); err != nil {
+			return nil, errors.Errorf("package %s not found%s", target.PackagePath,
+				didYouMean(target.PackagePath, importedPaths(pkg)))
+		}
+	}
+	unqualified := target.PackagePath == ""
+
+	if target.TypeName == "" {
+		if fn, ok := targetPkg.Scope().Lookup(target.FuncName).(*types.Func); ok {
+			return fn, nil
+		}
+		if unqualified {
+			return nil, nil
+		}
+		return nil, errors.Errorf("package %s has no function %s%s", targetPkg.Path(), target.FuncName,
+			didYouMean(target.FuncName, scopeNames[*types.Func](targetPkg.Scope())))
+	}
+
+	typeName, ok := targetPkg.Scope().Lookup(target.TypeName).(*types.TypeName)
+	if !ok {
+		if unqualified {
+			return nil, nil
+		}
+		return nil, errors.Errorf("package %s has no type %s%s", targetPkg.Path(), target.TypeName,
+			didYouMean(target.TypeName, scopeNames[*types.TypeName](targetPkg.Scope())))
+	}
+
+	mset := types.NewMethodSet(types.NewPointer(typeName.Type()))
+	if types.IsInterface(typeName.Type()) {
+		mset = types.NewMethodSet(typeName.Type())
+	}
+	var methods []string
+	for i := 0; i < mset.Len(); i++ {
+		method := mset.At(i).Obj().(*types.Func)
+		if method.Name() == target.FuncName {
+			if types.IsInterface(typeName.Type()) {
+				// interface methods have no body to verify
+				return nil, nil
+			}
+			return method, nil
+		}
+		methods = append(methods, method.Name())
+	}
+	if unqualified {
+		return nil, nil
+	}
+	return nil, errors.Errorf("type %s.%s has no method %s%s", targetPkg.Path(), target.TypeName, target.FuncName,
+		didYouMean(target.FuncName, methods))
+}
+
+// scopeNames lists the names in scope declaring objects of type T.
+func scopeNames[T types.Object](scope *types.Scope) []string {
+	var names []string
+	for _, name := range scope.Names() {
+		if _, ok := scope.Lookup(name).(T); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// importedPaths lists the paths of pkg and its transitive imports.
+func importedPaths(pkg *types.Package) []string {
+	var paths []string
+	seen := map[*types.Package]bool{pkg: true}
+	queue := []*types.Package{pkg}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		paths = append(paths, current.Path())
+		for _, imp := range current.Imports() {
+			if !seen[imp] {
+				seen[imp] = true
+				queue = append(queue, imp)
+			}
+		}
+	}
+	return paths
+}
+
+func didYouMean(name string, candidates []string) string {
+	suggestions := utils.Closest(name, candidates, 3)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+}
+
+// findFuncDecl returns the declaration of fn if it is in the analyzed package.
+func findFuncDecl(pass *analysis.Pass, fn *types.Func) *ast.FuncDecl {
+	if fn.Pkg() != pass.Pkg {
+		return nil
+	}
+	for _, file := range pass.Files {
+		for _, d := range file.Decls {
+			if fd, ok := d.(*ast.FuncDecl); ok && fd.Name.Pos() == fn.Pos() && fd.Body != nil {
+				return fd
+			}
+		}
+	}
+	return nil
+}
+
+// returnedFuncLits collects the function literals a handler factory returns.
+func returnedFuncLits(body *ast.BlockStmt) []*ast.BlockStmt {
+	var bodies []*ast.BlockStmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			for _, result := range node.Results {
+				if lit, ok := result.(*ast.FuncLit); ok {
+					bodies = append(bodies, lit.Body)
+				}
+			}
+		}
+		return true
+	})
+	return bodies
+}
+
+// directRecoverCalls returns the recover() calls made by body itself, outside
+// nested function literals.
+func directRecoverCalls(body *ast.BlockStmt, typeInfo *types.Info) []*ast.CallExpr {
+	var calls []*ast.CallExpr
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if isRecoverCall(node, typeInfo) {
+				calls = append(calls, node)
+			}
+		}
+		return true
+	})
+	return calls
+}
+
+func callsRecoverIndirectly(body *ast.BlockStmt, typeInfo *types.Info) bool {
+	found := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && isRecoverCall(call, typeInfo) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isRecoverCall(call *ast.CallExpr, typeInfo *types.Info) bool {
+	ident, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || ident.Name != "recover" {
+		return false
+	}
+	if typeInfo == nil {
+		return true
+	}
+	_, isBuiltin := typeInfo.Uses[ident].(*types.Builtin)
+	return isBuiltin
+}
+
+// recoveredValueDiscarded reports whether the value of a recover() call is
+// dropped: not used at all, assigned to _, or only compared against nil.
+func recoveredValueDiscarded(body *ast.BlockStmt, call *ast.CallExpr, typeInfo *types.Info) bool {
+	var expr ast.Node = call
+	parent := parentNode(body, expr)
+	for {
+		paren, ok := parent.(*ast.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = paren
+		parent = parentNode(body, paren)
+	}
+
+	switch node := parent.(type) {
+	case *ast.ExprStmt:
+		return true
+	case *ast.BinaryExpr:
+		return (node.Op == token.EQL || node.Op == token.NEQ) && (isNilIdent(node.X) || isNilIdent(node.Y))
+	case *ast.AssignStmt:
+		for i, rhs := range node.Rhs {
+			if rhs == expr && i < len(node.Lhs) {
+				return holderDiscarded(body, node.Lhs[i], typeInfo)
+			}
+		}
+	case *ast.ValueSpec:
+		for i, value := range node.Values {
+			if value == expr && i < len(node.Names) {
+				return holderDiscarded(body, node.Names[i], typeInfo)
+			}
+		}
+	}
+	return false
+}
+
+// holderDiscarded reports whether the variable a recovered value is assigned
+// to is _ or never used beyond nil checks. Assignments to anything other than
+// a plain variable count as using the value.
+func holderDiscarded(body *ast.BlockStmt, lhs ast.Expr, typeInfo *types.Info) bool {
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if ident.Name == "_" {
+		return true
+	}
+	if typeInfo == nil {
+		return false
+	}
+	obj := typeInfo.ObjectOf(ident)
+	return obj != nil && !usedBeyondNilCheck(body, obj, typeInfo)
+}
+
+// usedBeyondNilCheck reports whether obj is read anywhere other than in a
+// comparison against nil.
+func usedBeyondNilCheck(body *ast.BlockStmt, obj types.Object, typeInfo *types.Info) bool {
+	nilChecks := map[*ast.Ident]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		bin, ok := n.(*ast.BinaryExpr)
+		if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) {
+			return true
+		}
+		if x, ok := ast.Unparen(bin.X).(*ast.Ident); ok && isNilIdent(bin.Y) {
+			nilChecks[x] = true
+		}
+		if y, ok := ast.Unparen(bin.Y).(*ast.Ident); ok && isNilIdent(bin.X) {
+			nilChecks[y] = true
+		}
+		return true
+	})
+
+	used := false
+	ast.Inspect(body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if ok && !nilChecks[ident] && typeInfo.Uses[ident] == obj {
+			used = true
+		}
+		return !used
+	})
+	return used
+}
+
+func isNilIdent(expr ast.Expr) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
+// parentNode returns the node directly enclosing child within root.
+func parentNode(root ast.Node, child ast.Node) ast.Node {
+	var stack []ast.Node
+	var parent ast.Node
+	ast.Inspect(root, func(n ast.Node) bool {
+		if parent != nil {
+			return false
+		}
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if n == child && len(stack) > 0 {
+			parent = stack[len(stack)-1]
+			return false
+		}
+		stack = append(stack, n)
+		return true
+	})
+	return parent
+}
@@ -0,0 +1,325 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// labelPolicy holds the rules for the goroutine label passed to the handler,
+// as in defer HandlePanic("worker"). Dashboards group panics by label, so it
+// must be a constant string, may have to match a pattern and may have to be
+// unique within the package or the module.
+type labelPolicy struct {
+	// arg is the index of the label among the handler arguments; -1
+	// disables the policy.
+	arg     int
+	pattern regexpFlag
+	unique  uniqueScope
+
+	mu sync.Mutex
+	// sites records the labels of each package being analyzed, by argument
+	// position.
+	sites map[*analysis.Pass]map[token.Position]labelSite
+}
+
+// labelSite is a label argument. Positions are kept as file offsets, which
+// stay valid in passes that load the packages into a different file set.
+type labelSite struct {
+	Label string
+	Start token.Position
+	End   token.Position
+}
+
+// labelsFact lists the labels used in a package and in the packages it
+// imports, so that module uniqueness is checked by the importing package
+// whatever order the packages are analyzed in.
+type labelsFact struct {
+	Sites []labelSite
+}
+
+func (*labelsFact) AFact() {}
+
+func (f *labelsFact) String() string {
+	var labels []string
+	seen := map[string]bool{}
+	for _, site := range f.Sites {
+		if !seen[site.Label] {
+			seen[site.Label] = true
+			labels = append(labels, site.Label)
+		}
+	}
+	sort.Strings(labels)
+	return "labels " + strings.Join(labels, ", ")
+}
+
+const (
+	uniqueInPackage = "package"
+	uniqueInModule  = "module"
+)
+
+// uniqueScope is the flag.Value for -label-unique.
+type uniqueScope string
+
+func (u *uniqueScope) String() string {
+	return string(*u)
+}
+
+func (u *uniqueScope) Set(s string) error {
+	switch s {
+	case "", uniqueInPackage, uniqueInModule:
+		*u = uniqueScope(s)
+		return nil
+	}
+	return errors.Errorf("unknown uniqueness scope '%s', expected %s or %s", s, uniqueInPackage, uniqueInModule)
+}
+
+// regexpFlag is a flag.Value holding an optional regular expression.
+type regexpFlag struct {
+	*regexp.Regexp
+}
+
+func (r *regexpFlag) String() string {
+	if r.Regexp == nil {
+		return ""
+	}
+	return r.Regexp.String()
+}
+
+func (r *regexpFlag) Set(s string) error {
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return errors.Wrap(err, "invalid pattern")
+	}
+	r.Regexp = re
+	return nil
+}
+
+// checkLabel applies the label policy to the arguments of a handler defer in
+// the analyzed package. For factories the label is passed to the factory.
+func (p *Analyzer) checkLabel(pass *analysis.Pass, deferStmt *ast.DeferStmt, typeInfo *types.Info) {
+	policy := &p.labels
+	if policy.arg < 0 || typeInfo != pass.TypesInfo {
+		return
+	}
+
+	call := deferStmt.Call
+	if factoryCall, ok := call.Fun.(*ast.CallExpr); ok {
+		call = factoryCall
+	}
+	if policy.arg >= len(call.Args) {
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("handler call has no goroutine label argument at index %d", policy.arg),
+		})
+		return
+	}
+
+	arg := call.Args[policy.arg]
+	value := typeInfo.Types[arg].Value
+	if value == nil || value.Kind() != constant.String {
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:     arg.Pos(),
+			End:     arg.End(),
+			Message: fmt.Sprintf("goroutine label %s is not a constant string", types.ExprString(arg)),
+		})
+		return
+	}
+
+	label := constant.StringVal(value)
+	if policy.pattern.Regexp != nil && !policy.pattern.MatchString(label) {
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:     arg.Pos(),
+			End:     arg.End(),
+			Message: fmt.Sprintf("goroutine label %q does not match pattern '%s'", label, policy.pattern.String()),
+		})
+	}
+
+	if policy.unique == "" {
+		return
+	}
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if policy.sites == nil {
+		policy.sites = map[*analysis.Pass]map[token.Position]labelSite{}
+	}
+	if policy.sites[pass] == nil {
+		policy.sites[pass] = map[token.Position]labelSite{}
+	}
+	start := pass.Fset.Position(arg.Pos())
+	policy.sites[pass][start] = labelSite{Label: label, Start: start, End: pass.Fset.Position(arg.End())}
+}
+
+// reportDuplicateLabels reports the labels of the analyzed package that are
+// used more than once in the configured scope, pointing at the other sites.
+// With module scope, labels of imported packages come from their labelsFact:
+// a label shared with an imported package is reported at the site in the
+// importing package, and one shared by imported packages that do not import
+// each other at the package clause of the first package importing both.
+func (p *Analyzer) reportDuplicateLabels(pass *analysis.Pass) {
+	policy := &p.labels
+	if policy.unique == "" {
+		return
+	}
+
+	policy.mu.Lock()
+	var own []labelSite
+	for _, site := range policy.sites[pass] {
+		own = append(own, site)
+	}
+	delete(policy.sites, pass)
+	policy.mu.Unlock()
+	sortSites(own)
+
+	// imports holds the sites of each direct import, which include the
+	// sites of the packages it imports in turn
+	var imports [][]labelSite
+	if policy.unique == uniqueInModule {
+		for _, imp := range pass.Pkg.Imports() {
+			var fact labelsFact
+			if pass.ImportPackageFact(imp, &fact) {
+				imports = append(imports, fact.Sites)
+			}
+		}
+	}
+	imported := mergeSites(imports...)
+
+	all := mergeSites(own, imported)
+	byLabel := map[string][]labelSite{}
+	for _, site := range all {
+		byLabel[site.Label] = append(byLabel[site.Label], site)
+	}
+
+	for _, site := range own {
+		if len(byLabel[site.Label]) < 2 {
+			continue
+		}
+		var related []analysis.RelatedInformation
+		for _, other := range byLabel[site.Label] {
+			if other.Start != site.Start {
+				related = append(related, p.alsoUsedHere(pass, other)...)
+			}
+		}
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:     filePos(pass.Fset, site.Start),
+			End:     filePos(pass.Fset, site.End),
+			Message: fmt.Sprintf("goroutine label %q is not unique in the %s", site.Label, string(policy.unique)),
+			Related: related,
+		})
+	}
+
+	for _, label := range sortedKeys(byLabel) {
+		sites := byLabel[label]
+		if len(sites) < 2 || containsLabel(own, label) || reportedByImport(imports, sites) {
+			continue
+		}
+		var related []analysis.RelatedInformation
+		for _, site := range sites {
+			related = append(related, p.alsoUsedHere(pass, site)...)
+		}
+		p.reportOnce(pass, analysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: fmt.Sprintf("goroutine label %q is not unique in the module: imported packages use it %d times", label, len(sites)),
+			Related: related,
+		})
+	}
+
+	if policy.unique == uniqueInModule && len(all) > 0 {
+		pass.ExportPackageFact(&labelsFact{Sites: all})
+	}
+}
+
+func (p *Analyzer) alsoUsedHere(pass *analysis.Pass, site labelSite) []analysis.RelatedInformation {
+	pos := filePos(pass.Fset, site.Start)
+	if !pos.IsValid() {
+		return nil
+	}
+	return []analysis.RelatedInformation{{Pos: pos, End: filePos(pass.Fset, site.End), Message: "also used here"}}
+}
+
+// reportedByImport reports whether a single import already holds all sites,
+// so the duplicate was reported while analyzing it.
+func reportedByImport(imports [][]labelSite, sites []labelSite) bool {
+	for _, imp := range imports {
+		held := 0
+		for _, site := range sites {
+			for _, other := range imp {
+				if other.Start == site.Start {
+					held++
+					break
+				}
+			}
+		}
+		if held == len(sites) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLabel(sites []labelSite, label string) bool {
+	for _, site := range sites {
+		if site.Label == label {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSites joins site lists without repeating a position, in position order.
+func mergeSites(lists ...[]labelSite) []labelSite {
+	var merged []labelSite
+	seen := map[token.Position]bool{}
+	for _, list := range lists {
+		for _, site := range list {
+			if !seen[site.Start] {
+				seen[site.Start] = true
+				merged = append(merged, site)
+			}
+		}
+	}
+	sortSites(merged)
+	return merged
+}
+
+func sortSites(sites []labelSite) {
+	sort.Slice(sites, func(i, j int) bool {
+		if sites[i].Start.Filename != sites[j].Start.Filename {
+			return sites[i].Start.Filename < sites[j].Start.Filename
+		}
+		return sites[i].Start.Offset < sites[j].Start.Offset
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// filePos maps a recorded position back into fset, or returns token.NoPos
+// when fset does not hold the file.
+func filePos(fset *token.FileSet, position token.Position) token.Pos {
+	pos := token.NoPos
+	fset.Iterate(func(f *token.File) bool {
+		if f.Name() != position.Filename {
+			return true
+		}
+		pos = f.Pos(position.Offset)
+		return false
+	})
+	return pos
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// reportNonDeferredHandlers reports calls to the handler that are not
+// deferred. recover() returns nil outside a deferred call, so such a call
+// recovers nothing. Calls in deferred closures are left to the wrapper check,
+// and calls inside the handlers themselves are delegation.
+func (p *Analyzer) reportNonDeferredHandlers(pass *analysis.Pass, inspected *inspector.Inspector) {
+	if !p.nonDeferred {
+		return
+	}
+
+	inspected.WithStack([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		call := n.(*ast.CallExpr)
+		if p.matchTargetCall(call.Fun, pass.TypesInfo) != nil {
+			return true
+		}
+		switch parent := stack[len(stack)-2].(type) {
+		case *ast.DeferStmt:
+			if parent.Call == call {
+				return true
+			}
+		case *ast.CallExpr:
+			// the factory call in defer factory(...)()
+			if parent.Fun == call {
+				return true
+			}
+		}
+		if p.inDeferredLiteralOrHandler(stack, pass.TypesInfo) {
+			return true
+		}
+
+		handler := types.ExprString(call)
+		diagnostic := analysis.Diagnostic{
+			Pos:      call.Pos(),
+			End:      call.End(),
+			Category: "non-deferred",
+			Message: fmt.Sprintf("call to %s is not deferred: recover() returns nil outside a deferred call, so it recovers nothing; use defer %s",
+				handler, handler),
+		}
+		if stmt, ok := stack[len(stack)-2].(*ast.ExprStmt); ok && stmt.X == call {
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: fmt.Sprintf("Defer %s", types.ExprString(call.Fun)),
+				TextEdits: []analysis.TextEdit{{
+					Pos:     call.Pos(),
+					End:     call.Pos(),
+					NewText: []byte("defer "),
+				}},
+			}}
+		}
+		p.reportOnce(pass, diagnostic)
+		return true
+	})
+}
+
+// inDeferredLiteralOrHandler reports whether the innermost function on stack
+// is a literal called by a defer statement or a declaration of a handler.
+func (p *Analyzer) inDeferredLiteralOrHandler(stack []ast.Node, typeInfo *types.Info) bool {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch fn := stack[i].(type) {
+		case *ast.FuncLit:
+			if i < 2 {
+				return false
+			}
+			call, ok := stack[i-1].(*ast.CallExpr)
+			if !ok || call.Fun != fn {
+				return false
+			}
+			deferStmt, ok := stack[i-2].(*ast.DeferStmt)
+			return ok && deferStmt.Call == call
+		case *ast.FuncDecl:
+			obj, ok := typeInfo.Defs[fn.Name].(*types.Func)
+			return ok && (p.matchFuncObject(p.targets, obj, false) == nil || p.matchFuncObject(p.targets, obj, true) == nil)
+		}
+	}
+	return false
+}
@@ -0,0 +1,444 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+)
+
+// panicChecker decides whether evaluating code can panic. It is conservative:
+// anything it cannot prove safe is reported with the reason.
+type panicChecker struct {
+	info *types.Info
+	// files are searched for the assignments of variables whose values must
+	// be proven non-nil; nil outside the analyzed package.
+	files []*ast.File
+	// nilReceivers drops the assumption that method receivers are not nil.
+	nilReceivers bool
+	// call decides calls other than builtins and conversions.
+	call func(call *ast.CallExpr) error
+}
+
+// expr explains why evaluating e may panic, or returns nil.
+func (c *panicChecker) expr(e ast.Expr) error {
+	switch e := e.(type) {
+	case nil, *ast.BasicLit, *ast.FuncLit, *ast.Ident:
+		return nil
+	case *ast.ParenExpr:
+		return c.expr(e.X)
+	case *ast.KeyValueExpr:
+		if err := c.expr(e.Key); err != nil {
+			return err
+		}
+		return c.expr(e.Value)
+	case *ast.CompositeLit:
+		for _, elt := range e.Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok && c.isStructField(kv.Key) {
+				elt = kv.Value
+			}
+			if err := c.expr(elt); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *ast.UnaryExpr:
+		if e.Op == token.ARROW && !c.nonNil(e.X) {
+			// a nil channel blocks forever; treated as unsafe all the same
+			return errors.Errorf("channel %s may be nil", types.ExprString(e.X))
+		}
+		return c.expr(e.X)
+	case *ast.BinaryExpr:
+		if err := c.binary(e); err != nil {
+			return err
+		}
+		if err := c.expr(e.X); err != nil {
+			return err
+		}
+		return c.expr(e.Y)
+	case *ast.SelectorExpr:
+		return c.selector(e)
+	case *ast.IndexExpr:
+		return c.index(e.X, e.Index, e)
+	case *ast.IndexListExpr:
+		if c.isGenericInstance(e.X) {
+			return nil
+		}
+		return errors.Errorf("index %s may be out of range", types.ExprString(e))
+	case *ast.StarExpr:
+		if c.info.Types[e].IsType() {
+			return nil
+		}
+		if !c.nonNil(e.X) {
+			return errors.Errorf("%s may be nil", types.ExprString(e.X))
+		}
+		return c.expr(e.X)
+	case *ast.SliceExpr:
+		return errors.Errorf("slice %s may be out of range", types.ExprString(e))
+	case *ast.TypeAssertExpr:
+		return errors.Errorf("type assertion %s may fail", types.ExprString(e))
+	case *ast.CallExpr:
+		return c.callExpr(e)
+	default:
+		return errors.Errorf("cannot prove %s is panic-free", types.ExprString(e))
+	}
+}
+
+func (c *panicChecker) isStructField(key ast.Expr) bool {
+	ident, ok := key.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	v, ok := c.info.ObjectOf(ident).(*types.Var)
+	return ok && v.IsField()
+}
+
+func (c *panicChecker) isGenericInstance(x ast.Expr) bool {
+	tv := c.info.Types[x]
+	if tv.IsType() {
+		return true
+	}
+	_, isFunc := tv.Type.(*types.Signature)
+	return isFunc
+}
+
+// binary rejects integer division by a value that may be zero, shifts by a
+// count that may be negative and comparisons that may panic.
+func (c *panicChecker) binary(e *ast.BinaryExpr) error {
+	switch e.Op {
+	case token.QUO, token.REM:
+		if divisionMayPanic(c.info.TypeOf(e), c.info.Types[e.Y].Value) {
+			return errors.Errorf("division by %s may panic", types.ExprString(e.Y))
+		}
+	case token.SHL, token.SHR:
+		if shiftMayPanic(c.info.TypeOf(e.Y), c.info.Types[e.Y].Value) {
+			return errors.Errorf("shift by %s may panic", types.ExprString(e.Y))
+		}
+	case token.EQL, token.NEQ:
+		if c.comparisonMayPanic(e.X, e.Y) {
+			return errors.Errorf("comparison %s may panic on incomparable dynamic types", types.ExprString(e))
+		}
+	}
+	return nil
+}
+
+// assignTarget checks the target of an assignment: writes to possibly nil
+// maps panic, and so do writes through possibly nil pointers.
+func (c *panicChecker) assignTarget(target ast.Expr) error {
+	index, ok := ast.Unparen(target).(*ast.IndexExpr)
+	if !ok {
+		return c.expr(target)
+	}
+	if _, isMap := c.info.TypeOf(index.X).Underlying().(*types.Map); !isMap {
+		return c.expr(target)
+	}
+	if err := c.expr(index.X); err != nil {
+		return err
+	}
+	if err := c.expr(index.Index); err != nil {
+		return err
+	}
+	if err := c.mapKey(index.Index); err != nil {
+		return err
+	}
+	if !c.nonNil(index.X) {
+		return errors.Errorf("map %s may be nil", types.ExprString(index.X))
+	}
+	return nil
+}
+
+// mapKey checks a map index: hashing a key that holds an interface panics
+// when its dynamic type is not comparable.
+func (c *panicChecker) mapKey(key ast.Expr) error {
+	if !c.info.Types[key].IsNil() && mapKeyMayPanic(c.info.TypeOf(key)) {
+		return errors.Errorf("map key %s may panic on an incomparable dynamic type", types.ExprString(key))
+	}
+	return nil
+}
+
+func (c *panicChecker) comparisonMayPanic(x, y ast.Expr) bool {
+	withNil := c.info.Types[x].IsNil() || c.info.Types[y].IsNil()
+	return comparisonMayPanic(c.info.TypeOf(x), c.info.TypeOf(y), withNil)
+}
+
+// selector allows package-qualified names and field or method reads whose
+// operand is not a possibly-nil pointer and that do not go through embedded
+// pointers or interfaces, which may be nil.
+func (c *panicChecker) selector(e *ast.SelectorExpr) error {
+	if ident, ok := e.X.(*ast.Ident); ok {
+		if _, isPkg := c.info.Uses[ident].(*types.PkgName); isPkg {
+			return nil
+		}
+	}
+
+	if sel := c.info.Selections[e]; sel != nil {
+		if _, isPointer := c.info.TypeOf(e.X).Underlying().(*types.Pointer); isPointer && !c.nonNil(e.X) {
+			return errors.Errorf("%s may be nil", types.ExprString(e.X))
+		}
+		if field := embeddedNilable(sel); field != nil {
+			return errors.Errorf("embedded %s of %s may be nil", field.Name(), types.ExprString(e.X))
+		}
+	}
+	return c.expr(e.X)
+}
+
+// embeddedNilable returns the first embedded pointer or interface field the
+// selection goes through to reach a promoted field or method.
+func embeddedNilable(sel *types.Selection) *types.Var {
+	t := sel.Recv()
+	index := sel.Index()
+	for _, i := range index[:len(index)-1] {
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		st, ok := t.Underlying().(*types.Struct)
+		if !ok {
+			return nil
+		}
+		field := st.Field(i)
+		switch field.Type().Underlying().(type) {
+		case *types.Pointer, *types.Interface:
+			return field
+		}
+		t = field.Type()
+	}
+	return nil
+}
+
+// index allows map reads and constant in-range indexes into arrays.
+func (c *panicChecker) index(x, index ast.Expr, e ast.Expr) error {
+	if c.isGenericInstance(x) {
+		return nil
+	}
+	if err := c.expr(x); err != nil {
+		return err
+	}
+	if err := c.expr(index); err != nil {
+		return err
+	}
+
+	switch t := c.info.TypeOf(x).Underlying().(type) {
+	case *types.Map:
+		return c.mapKey(index)
+	case *types.Array:
+		if idx := c.info.Types[index].Value; idx != nil {
+			if i, ok := constant.Int64Val(idx); ok && i >= 0 && i < t.Len() {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf("index %s may be out of range", types.ExprString(e))
+}
+
+func (c *panicChecker) callExpr(e *ast.CallExpr) error {
+	if c.info.Types[e.Fun].IsType() {
+		// slices convert to arrays and array pointers only when long enough
+		target := c.info.TypeOf(e).Underlying()
+		if ptr, ok := target.(*types.Pointer); ok {
+			target = ptr.Elem().Underlying()
+		}
+		if _, toArray := target.(*types.Array); toArray && len(e.Args) == 1 {
+			if _, fromSlice := c.info.TypeOf(e.Args[0]).Underlying().(*types.Slice); fromSlice {
+				return errors.Errorf("conversion %s may panic", types.ExprString(e))
+			}
+		}
+		return c.args(e)
+	}
+
+	if ident, ok := ast.Unparen(e.Fun).(*ast.Ident); ok {
+		if builtin, ok := c.info.Uses[ident].(*types.Builtin); ok {
+			return c.builtin(builtin.Name(), e)
+		}
+	}
+
+	if c.call == nil {
+		return errors.Errorf("call to %s may panic", types.ExprString(e.Fun))
+	}
+	return c.call(e)
+}
+
+func (c *panicChecker) builtin(name string, e *ast.CallExpr) error {
+	switch name {
+	case "close", "panic":
+		return errors.Errorf("%s may panic", types.ExprString(e))
+	case "make":
+		var sizes []constant.Value
+		for _, size := range e.Args[1:] {
+			sizes = append(sizes, c.info.Types[size].Value)
+		}
+		if makeMayPanic(sizes...) {
+			return errors.Errorf("%s may panic on a negative size", types.ExprString(e))
+		}
+		return nil
+	case "new":
+		return nil
+	}
+	return c.args(e)
+}
+
+// args checks the arguments of a call.
+func (c *panicChecker) args(e *ast.CallExpr) error {
+	for _, arg := range e.Args {
+		if err := c.expr(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// operands checks what evaluating a call needs besides the callee's own
+// body: its receiver, which must not be a possibly-nil pointer or interface,
+// and its arguments.
+func (c *panicChecker) operands(e *ast.CallExpr) error {
+	if sel, ok := unindex(e.Fun).(*ast.SelectorExpr); ok {
+		if selection := c.info.Selections[sel]; selection != nil && selection.Kind() == types.MethodVal {
+			if isNilable(c.info.TypeOf(sel.X)) && !c.nonNil(sel.X) {
+				return errors.Errorf("receiver %s may be nil", types.ExprString(sel.X))
+			}
+		} else if selection != nil && selection.Kind() == types.MethodExpr && len(e.Args) > 0 {
+			// the receiver of (*T).m(x) is its first argument
+			if isNilable(c.info.TypeOf(e.Args[0])) && !c.nonNil(e.Args[0]) {
+				return errors.Errorf("receiver %s may be nil", types.ExprString(e.Args[0]))
+			}
+		}
+		if err := c.selector(sel); err != nil {
+			return err
+		}
+	}
+	return c.args(e)
+}
+
+func isNilable(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Interface, *types.Map, *types.Chan, *types.Signature, *types.Slice:
+		return true
+	}
+	return false
+}
+
+// nonNil reports whether e provably is not nil: it is not of a nilable type,
+// takes an address, allocates with new, is the receiver of the enclosing
+// method, or is a variable only ever assigned such values in the package.
+func (c *panicChecker) nonNil(e ast.Expr) bool {
+	return c.nonNilDepth(e, 0)
+}
+
+func (c *panicChecker) nonNilDepth(e ast.Expr, depth int) bool {
+	e = ast.Unparen(e)
+	// untyped nil is not of a nilable type, so check for it first
+	if tv, ok := c.info.Types[e]; ok && tv.IsNil() {
+		return false
+	}
+	if t := c.info.TypeOf(e); t == nil || !isNilable(t) {
+		return t != nil
+	}
+
+	switch e := e.(type) {
+	case *ast.UnaryExpr:
+		return e.Op == token.AND
+	case *ast.CallExpr:
+		if ident, ok := ast.Unparen(e.Fun).(*ast.Ident); ok {
+			if builtin, ok := c.info.Uses[ident].(*types.Builtin); ok {
+				return builtin.Name() == "new" || builtin.Name() == "make"
+			}
+		}
+	case *ast.CompositeLit, *ast.FuncLit:
+		return true
+	case *ast.SelectorExpr:
+		// function and method values are never nil
+		_, isFunc := c.info.Uses[e.Sel].(*types.Func)
+		return isFunc
+	case *ast.Ident:
+		if _, isFunc := c.info.Uses[e].(*types.Func); isFunc {
+			return true
+		}
+		v, ok := c.info.Uses[e].(*types.Var)
+		if !ok || depth > 3 {
+			return false
+		}
+		if !c.nilReceivers && c.isReceiver(v) {
+			return true
+		}
+		values, ok := c.assignedValues(v)
+		if !ok || len(values) == 0 {
+			return false
+		}
+		for _, value := range values {
+			if !c.nonNilDepth(value, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isReceiver reports whether v is the receiver of a method declared in the
+// package. A method called on a nil receiver rarely gets as far as starting
+// a goroutine, so receivers count as non-nil.
+func (c *panicChecker) isReceiver(v *types.Var) bool {
+	for _, file := range c.files {
+		for _, d := range file.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil {
+				continue
+			}
+			for _, field := range fd.Recv.List {
+				for _, name := range field.Names {
+					if c.info.Defs[name] == v {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// assignedValues collects every value assigned to v in the package. It fails
+// if v is declared without a value, assigned from a multi-value expression or
+// has its address taken, as it may then be set through the pointer.
+func (c *panicChecker) assignedValues(v *types.Var) ([]ast.Expr, bool) {
+	var values []ast.Expr
+	ok := true
+	for _, file := range c.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range node.Lhs {
+					ident, isIdent := lhs.(*ast.Ident)
+					if !isIdent || c.info.ObjectOf(ident) != v {
+						continue
+					}
+					if len(node.Lhs) != len(node.Rhs) {
+						ok = false
+						continue
+					}
+					values = append(values, node.Rhs[i])
+				}
+			case *ast.ValueSpec:
+				for i, name := range node.Names {
+					if c.info.Defs[name] != v {
+						continue
+					}
+					if len(node.Names) != len(node.Values) {
+						ok = false
+						continue
+					}
+					values = append(values, node.Values[i])
+				}
+			case *ast.UnaryExpr:
+				if ident, isIdent := ast.Unparen(node.X).(*ast.Ident); isIdent && node.Op == token.AND && c.info.ObjectOf(ident) == v {
+					ok = false
+				}
+			}
+			return ok
+		})
+	}
+	return values, ok
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"go/constant"
+	"go/types"
+)
+
+// The rules below decide which operations on values may panic. The panic-free
+// proof works on syntax and the -ssa check on SSA instructions; both ask these
+// rules so that they agree. Constant operands are passed as their value, and
+// operands that are not constant as nil.
+
+// divisionMayPanic reports whether an integer division or remainder of type t
+// by divisor may panic: only a non-zero constant divisor rules it out.
+func divisionMayPanic(t types.Type, divisor constant.Value) bool {
+	return isInteger(t) && (divisor == nil || constant.Sign(divisor) == 0)
+}
+
+// shiftMayPanic reports whether a shift by count may panic: signed counts
+// that are not constant may be negative.
+func shiftMayPanic(count types.Type, value constant.Value) bool {
+	basic, ok := count.Underlying().(*types.Basic)
+	return value == nil && ok && basic.Info()&types.IsUnsigned == 0
+}
+
+// comparisonMayPanic reports whether comparing values of types x and y may
+// panic: values holding interfaces panic when their dynamic types are
+// identical but not comparable. Comparisons with nil cannot panic.
+func comparisonMayPanic(x, y types.Type, withNil bool) bool {
+	return !withNil && (holdsInterface(x) || holdsInterface(y))
+}
+
+// mapKeyMayPanic reports whether hashing a map key of type key, to read or
+// write the map, may panic on an incomparable dynamic type.
+func mapKeyMayPanic(key types.Type) bool {
+	return holdsInterface(key)
+}
+
+// makeMayPanic reports whether make with the given sizes may panic: sizes
+// that are not constant may be negative or too large.
+func makeMayPanic(sizes ...constant.Value) bool {
+	for _, size := range sizes {
+		if size == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsInterface reports whether values of t are, or contain, interfaces.
+func holdsInterface(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Interface:
+		return true
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if holdsInterface(u.Field(i).Type()) {
+				return true
+			}
+		}
+	case *types.Array:
+		return holdsInterface(u.Elem())
+	}
+	return false
+}
+
+func isInteger(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
@@ -0,0 +1,30 @@
+package analyzer
+
+import (
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkParamGoroutine treats a parameter started as a goroutine, as in
+// func spawn(f func()) { go f() }, as a sink: the arguments passed for it at
+// every call site in the package are checked, and findings are reported at the
+// argument rather than at the helper. Each parameter is checked once.
+func (p *Analyzer) checkParamGoroutine(pass *analysis.Pass, param *types.Var, fn *types.Func, index int) error {
+	if _, loaded := p.checkedParams.LoadOrStore(param, struct{}{}); loaded {
+		return nil
+	}
+
+	args := p.expandParams(pass, callArguments(pass, fn, index))
+	if len(args) == 0 {
+		p.logger.Printf("no call passes a function for parameter function=%s param=%s", fn.Name(), param.Name())
+		return nil
+	}
+	for _, arg := range args {
+		if err := p.checkFuncValue(pass, arg, arg.Pos()); err != nil {
+			p.logLinterError(pass, arg.Pos(), arg.Pos(), errors.Wrapf(err, "started as a goroutine by %s", fn.Name()))
+		}
+	}
+	return nil
+}
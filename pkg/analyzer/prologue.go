@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// skipPrologue returns the index of the first statement of body that is not
+// part of the allowed prologue: a call or defer of a -prologue function, or an
+// assignment from such calls. Prologue statements run before the handler is
+// registered, so each one is checked not to panic itself.
+func (p *Analyzer) skipPrologue(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) (int, error) {
+	if len(p.prologue) == 0 {
+		return 0, nil
+	}
+
+	checker := &panicChecker{info: typeInfo}
+	if typeInfo == pass.TypesInfo {
+		checker.files = pass.Files
+	}
+	checker.call = func(call *ast.CallExpr) error {
+		if err := p.matchCall(p.prologue, call.Fun, typeInfo); err != nil {
+			return errors.Errorf("call to %s is not in the prologue allow-list", types.ExprString(call.Fun))
+		}
+		return checker.operands(call)
+	}
+
+	for i, stmt := range body.List {
+		calls := p.prologueCalls(stmt, typeInfo)
+		if calls == nil {
+			return i, nil
+		}
+		for _, call := range calls {
+			if err := checker.operands(call); err != nil {
+				return i, errors.Wrapf(err, "statement before handler may panic")
+			}
+		}
+		if assign, ok := stmt.(*ast.AssignStmt); ok {
+			for _, lhs := range assign.Lhs {
+				if err := checker.assignTarget(lhs); err != nil {
+					return i, errors.Wrapf(err, "statement before handler may panic")
+				}
+			}
+		}
+	}
+	return len(body.List), nil
+}
+
+// prologueCalls returns the allowed calls stmt consists of, or nil if it is
+// not a prologue statement.
+func (p *Analyzer) prologueCalls(stmt ast.Stmt, typeInfo *types.Info) []*ast.CallExpr {
+	var exprs []ast.Expr
+	switch s := stmt.(type) {
+	case *ast.ExprStmt:
+		exprs = []ast.Expr{s.X}
+	case *ast.DeferStmt:
+		exprs = []ast.Expr{s.Call}
+	case *ast.AssignStmt:
+		if len(s.Lhs) != len(s.Rhs) {
+			return nil
+		}
+		exprs = s.Rhs
+	case *ast.DeclStmt:
+		decl, ok := s.Decl.(*ast.GenDecl)
+		if !ok {
+			return nil
+		}
+		for _, spec := range decl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Names) != len(valueSpec.Values) {
+				return nil
+			}
+			exprs = append(exprs, valueSpec.Values...)
+		}
+	}
+	if len(exprs) == 0 {
+		return nil
+	}
+
+	calls := make([]*ast.CallExpr, 0, len(exprs))
+	for _, expr := range exprs {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok || p.matchCall(p.prologue, call.Fun, typeInfo) != nil {
+			return nil
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
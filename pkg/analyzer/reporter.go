@@ -0,0 +1,178 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/cfg"
+)
+
+// checkInlineRecover accepts a deferred function literal that guards the
+// goroutine itself, as in
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			obs.Report(r)
+//		}
+//	}()
+//
+// The literal must call recover() directly and pass the recovered value to a
+// configured reporter on every path where it is non-nil.
+func (p *Analyzer) checkInlineRecover(lit *ast.FuncLit, typeInfo *types.Info) error {
+	calls := directRecoverCalls(lit.Body, typeInfo)
+	if len(calls) == 0 {
+		return errors.New("deferred closure does not call recover() directly")
+	}
+
+	for _, call := range calls {
+		if !p.forwardsRecoveredValue(lit.Body, call, typeInfo) {
+			return errors.Errorf("recovered value is not passed to %s on every path where it is non-nil", p.reporters.Description())
+		}
+	}
+	return nil
+}
+
+// forwardsRecoveredValue reports whether the value of a recover() call in body
+// reaches a reporter call on every path where it is non-nil.
+func (p *Analyzer) forwardsRecoveredValue(body *ast.BlockStmt, call *ast.CallExpr, typeInfo *types.Info) bool {
+	// obs.Report(recover()) forwards the value unconditionally
+	if parent, ok := parentNode(body, call).(*ast.CallExpr); ok && p.matchCall(p.reporters, parent.Fun, typeInfo) == nil {
+		return true
+	}
+
+	holder := recoveredValueHolder(body, call, typeInfo)
+	if holder == nil {
+		return false
+	}
+
+	graph := cfg.New(body, func(*ast.CallExpr) bool { return true })
+	for _, block := range graph.Blocks {
+		for i, node := range block.Nodes {
+			if containsNode(node, call) {
+				return p.reportsOnAllPaths(block, i+1, holder, typeInfo, map[*cfg.Block]bool{})
+			}
+		}
+	}
+	return false
+}
+
+// reportsOnAllPaths walks the control-flow graph from the given node of block
+// and reports whether every path that reaches the end of the function with
+// holder possibly non-nil passes holder to a reporter first. Branches on
+// holder == nil and holder != nil prune the paths where it is nil.
+func (p *Analyzer) reportsOnAllPaths(block *cfg.Block, start int, holder types.Object, typeInfo *types.Info, visited map[*cfg.Block]bool) bool {
+	for _, node := range block.Nodes[start:] {
+		if p.reportsValue(node, holder, typeInfo) {
+			return true
+		}
+	}
+	if len(block.Succs) == 0 {
+		return false
+	}
+
+	succs := block.Succs
+	if len(succs) == 2 && len(block.Nodes) > 0 {
+		if cond, ok := block.Nodes[len(block.Nodes)-1].(ast.Expr); ok {
+			switch nilComparison(cond, holder, typeInfo) {
+			case token.NEQ:
+				succs = succs[:1]
+			case token.EQL:
+				succs = succs[1:]
+			}
+		}
+	}
+
+	for _, succ := range succs {
+		if visited[succ] {
+			continue
+		}
+		visited[succ] = true
+		if !p.reportsOnAllPaths(succ, 0, holder, typeInfo, visited) {
+			return false
+		}
+	}
+	return true
+}
+
+// reportsValue reports whether node calls a reporter with an argument that
+// mentions holder.
+func (p *Analyzer) reportsValue(node ast.Node, holder types.Object, typeInfo *types.Info) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok || p.matchCall(p.reporters, call.Fun, typeInfo) != nil {
+			return !found
+		}
+		for _, arg := range call.Args {
+			if mentions(arg, holder, typeInfo) {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// recoveredValueHolder returns the variable the value of a recover() call is
+// assigned to, if any.
+func recoveredValueHolder(body *ast.BlockStmt, call *ast.CallExpr, typeInfo *types.Info) types.Object {
+	var lhs ast.Expr
+	switch node := parentNode(body, call).(type) {
+	case *ast.AssignStmt:
+		for i, rhs := range node.Rhs {
+			if rhs == call && i < len(node.Lhs) {
+				lhs = node.Lhs[i]
+			}
+		}
+	case *ast.ValueSpec:
+		for i, value := range node.Values {
+			if value == call && i < len(node.Names) {
+				lhs = node.Names[i]
+			}
+		}
+	}
+
+	ident, ok := lhs.(*ast.Ident)
+	if !ok || ident.Name == "_" {
+		return nil
+	}
+	return typeInfo.ObjectOf(ident)
+}
+
+// nilComparison returns the operator of cond if it compares obj against nil.
+func nilComparison(cond ast.Expr, obj types.Object, typeInfo *types.Info) token.Token {
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.EQL && bin.Op != token.NEQ) {
+		return token.ILLEGAL
+	}
+	if (isObjectIdent(bin.X, obj, typeInfo) && isNilIdent(bin.Y)) || (isNilIdent(bin.X) && isObjectIdent(bin.Y, obj, typeInfo)) {
+		return bin.Op
+	}
+	return token.ILLEGAL
+}
+
+func isObjectIdent(expr ast.Expr, obj types.Object, typeInfo *types.Info) bool {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	return ok && typeInfo.ObjectOf(ident) == obj
+}
+
+// mentions reports whether expr refers to obj.
+func mentions(expr ast.Node, obj types.Object, typeInfo *types.Info) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok && typeInfo.Uses[ident] == obj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func containsNode(root ast.Node, target ast.Node) bool {
+	return root.Pos() <= target.Pos() && target.End() <= root.End()
+}
@@ -0,0 +1,204 @@
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// step is a call every goroutine must make in its opening statements, such
+// as setting pprof labels or starting a tracing span.
+type step struct {
+	target Target
+	// deferred steps must be deferred, the others called directly.
+	deferred bool
+}
+
+func (s step) String() string {
+	if s.deferred {
+		return "defer:" + s.target.String()
+	}
+	return s.target.String()
+}
+
+// describe names the step in diagnostics.
+func (s step) describe() string {
+	if s.deferred {
+		return "defer " + s.target.String()
+	}
+	return s.target.String()
+}
+
+// steps is the ordered list of required steps. Each value is a target,
+// prefixed with "defer:" when the call must be deferred.
+type steps []step
+
+func (s steps) String() string {
+	items := make([]string, 0, len(s))
+	for _, st := range s {
+		items = append(items, st.String())
+	}
+	return strings.Join(items, ",")
+}
+
+func (s *steps) Set(value string) error {
+	for _, item := range splitList(value) {
+		item = strings.TrimSpace(item)
+		var st step
+		item, st.deferred = strings.CutPrefix(item, "defer:")
+		if err := st.target.Set(item); err != nil {
+			return errors.Wrapf(err, "invalid step '%s'", item)
+		}
+		*s = append(*s, st)
+	}
+	return nil
+}
+
+// linterErrors collects independent findings for one goroutine body; each is
+// reported on its own.
+type linterErrors []error
+
+func (e linterErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// checkSteps verifies that the opening statements of body, the simple
+// statements before the first control flow, make the required calls in
+// order. It returns one finding per missing or out-of-order step.
+func (p *Analyzer) checkSteps(pass *analysis.Pass, body *ast.BlockStmt, typeInfo *types.Info) []error {
+	if len(p.steps) == 0 || body == nil || len(body.List) == 0 {
+		return nil
+	}
+	local := typeInfo == pass.TypesInfo
+
+	found := make([]int, len(p.steps))
+	for i, st := range p.steps {
+		found[i] = -1
+		for j, stmt := range openingStatements(body) {
+			if p.stmtMakesStep(stmt, st, typeInfo) {
+				found[i] = j
+				break
+			}
+		}
+	}
+
+	var errs []error
+	ordered := inOrder(found)
+	for i, st := range p.steps {
+		if found[i] == -1 {
+			errs = append(errs, &guardError{diagnostic: analysis.Diagnostic{
+				Category: "policy",
+				Message:  fmt.Sprintf("goroutine does not start with required step %d of %d: %s", i+1, len(p.steps), st.describe()),
+			}})
+			continue
+		}
+		if ordered[i] {
+			continue
+		}
+
+		diagnostic := analysis.Diagnostic{Category: "policy"}
+		for j := range p.steps {
+			if !ordered[j] {
+				continue
+			}
+			if j > i && found[j] < found[i] {
+				diagnostic.Message = fmt.Sprintf("required step %s must come before %s", st.describe(), p.steps[j].describe())
+				break
+			}
+			if j < i && found[j] > found[i] {
+				diagnostic.Message = fmt.Sprintf("required step %s must come after %s", st.describe(), p.steps[j].describe())
+			}
+		}
+		if local {
+			diagnostic.Pos = body.List[found[i]].Pos()
+		}
+		errs = append(errs, &guardError{diagnostic: diagnostic})
+	}
+	return errs
+}
+
+// inOrder marks the largest set of found steps that already appear in the
+// configured order; the others are reported as out of order.
+func inOrder(found []int) []bool {
+	length := make([]int, len(found))
+	prev := make([]int, len(found))
+	best := -1
+	for i := range found {
+		prev[i] = -1
+		if found[i] == -1 {
+			continue
+		}
+		length[i] = 1
+		for j := 0; j < i; j++ {
+			if found[j] != -1 && found[j] <= found[i] && length[j]+1 > length[i] {
+				length[i] = length[j] + 1
+				prev[i] = j
+			}
+		}
+		if best == -1 || length[i] > length[best] {
+			best = i
+		}
+	}
+
+	ordered := make([]bool, len(found))
+	for i := best; i != -1; i = prev[i] {
+		ordered[i] = true
+	}
+	return ordered
+}
+
+// openingStatements returns the leading simple statements of body: calls,
+// assignments, declarations and defers.
+func openingStatements(body *ast.BlockStmt) []ast.Stmt {
+	for i, stmt := range body.List {
+		switch stmt.(type) {
+		case *ast.ExprStmt, *ast.AssignStmt, *ast.DeclStmt, *ast.DeferStmt, *ast.IncDecStmt, *ast.EmptyStmt:
+			continue
+		}
+		return body.List[:i]
+	}
+	return body.List
+}
+
+// stmtMakesStep reports whether stmt makes the call st requires: a deferred
+// call for deferred steps, or a call on its own or on the right-hand side of
+// an assignment otherwise.
+func (p *Analyzer) stmtMakesStep(stmt ast.Stmt, st step, typeInfo *types.Info) bool {
+	if _, isDefer := stmt.(*ast.DeferStmt); isDefer != st.deferred {
+		return false
+	}
+
+	var exprs []ast.Expr
+	switch s := stmt.(type) {
+	case *ast.DeferStmt:
+		exprs = []ast.Expr{s.Call}
+	case *ast.ExprStmt:
+		exprs = []ast.Expr{s.X}
+	case *ast.AssignStmt:
+		exprs = s.Rhs
+	case *ast.DeclStmt:
+		if decl, ok := s.Decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+			for _, spec := range decl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					exprs = append(exprs, valueSpec.Values...)
+				}
+			}
+		}
+	}
+	for _, expr := range exprs {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if ok && p.matchCall(Targets{st.target}, call.Fun, typeInfo) == nil {
+			return true
+		}
+	}
+	return false
+}
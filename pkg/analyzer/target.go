@@ -2,6 +2,9 @@ package analyzer
 
 import (
 	"fmt"
+	"go/token"
+	"go/types"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -9,25 +12,102 @@ import (
 
 const DefaultTarget = "HandlePanic"
 
+// Target identifies a panic handler. It is either a function, written
+// full/pkg/path.Func, or a method pinned to its receiver type, written
+// (*full/pkg/path.Type).Method, (full/pkg/path.Type).Method or
+// full/pkg/path.Type.Method. When the type is an interface, calls through the
+// interface and through any type implementing it match as well. A trailing
+// "()" marks a handler factory, whose result is deferred instead:
+// defer guard.Recover("worker")().
+//
+// A target may also be a pattern over the qualified handler name, which is
+// full/pkg/path.Func for functions and full/pkg/path.Type.Method for methods:
+// either a glob such as */internal/panics.Handle*, where * in the package
+// path also matches "/", or a regular expression prefixed with "re:".
 type Target struct {
 	PackagePath string
 	FuncName    string
+	// TypeName is the receiver type of a method target. An exported name
+	// before the function name, as in obs.PanicHandler.Handle, is taken as a
+	// type rather than a package path element.
+	TypeName string
+	// Pointer records whether a method target was written with a pointer
+	// receiver. A type cannot declare the same method on both T and *T, so
+	// it is not needed for matching.
+	Pointer bool
+	// Factory marks a function returning the handler rather than being one.
+	Factory bool
+	// Pattern is the glob or "re:" expression of a pattern target as written.
+	Pattern string
+
+	pattern *regexp.Regexp
 }
 
 func (t Target) String() string {
-	return fmt.Sprintf("%v.%v", t.PackagePath, t.FuncName)
+	suffix := ""
+	if t.Factory {
+		suffix = "()"
+	}
+	if t.Pattern != "" {
+		return t.Pattern + suffix
+	}
+	if t.TypeName != "" {
+		return fmt.Sprintf("(%s).%s%s", t.receiver(), t.FuncName, suffix)
+	}
+	if t.PackagePath == "" {
+		return t.FuncName + suffix
+	}
+	return fmt.Sprintf("%v.%v%v", t.PackagePath, t.FuncName, suffix)
+}
+
+func (t Target) receiver() string {
+	recv := t.TypeName
+	if t.PackagePath != "" {
+		recv = t.PackagePath + "." + recv
+	}
+	if t.Pointer {
+		recv = "*" + recv
+	}
+	return recv
 }
 
 func (t *Target) Set(s string) error {
 	t.PackagePath = ""
 	t.FuncName = ""
+	t.TypeName = ""
+	t.Pointer = false
+	t.Factory = false
+	t.Pattern = ""
+	t.pattern = nil
 
 	if s == "" {
 		return errors.New("empty target")
 	}
 
+	if trimmed, ok := strings.CutSuffix(s, "()"); ok {
+		t.Factory = true
+		s = trimmed
+	}
+	if s == "" {
+		return errors.New("target must include a function name")
+	}
+
+	if strings.HasPrefix(s, "re:") || (!strings.HasPrefix(s, "(") && strings.ContainsAny(s, "*?")) {
+		return t.setPattern(s)
+	}
+
+	// type parameters are not part of the handler name: HandlePanic[T]
+	s = stripTypeParams(s)
+
+	if strings.HasPrefix(s, "(") {
+		return t.setMethod(s)
+	}
+
 	idx := strings.LastIndex(s, ".")
 	if idx == -1 {
+		if s = strings.TrimSpace(s); s == "" {
+			return errors.New("target must include a function name")
+		}
 		t.FuncName = s
 		return nil
 	}
@@ -38,7 +118,365 @@ func (t *Target) Set(s string) error {
 		return errors.New("target must include a function name")
 	}
 
+	last := pkg[strings.LastIndex(pkg, "/")+1:]
+	if dot := strings.LastIndex(last, "."); dot != -1 && token.IsExported(last[dot+1:]) {
+		t.TypeName = last[dot+1:]
+		pkg = pkg[:len(pkg)-len(last)+dot]
+	}
+
 	t.PackagePath = pkg
 	t.FuncName = fn
 	return nil
 }
+
+// stripTypeParams removes bracketed type parameter lists, as in
+// full/pkg/path.HandlePanic[T] or (*full/pkg/path.Handler[T]).Handle.
+func stripTypeParams(s string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range s {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']' && depth > 0:
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// setMethod parses a method target such as (*full/pkg/path.Type).Method.
+func (t *Target) setMethod(s string) error {
+	end := strings.Index(s, ").")
+	if end == -1 {
+		return errors.New("method target must be in the form (full/pkg/path.Type).Method")
+	}
+
+	recv := strings.TrimSpace(s[1:end])
+	fn := strings.TrimSpace(s[end+2:])
+	if fn == "" {
+		return errors.New("target must include a method name")
+	}
+
+	if strings.HasPrefix(recv, "*") {
+		t.Pointer = true
+		recv = strings.TrimSpace(recv[1:])
+	}
+	if idx := strings.LastIndex(recv, "."); idx != -1 {
+		t.PackagePath = recv[:idx]
+		recv = recv[idx+1:]
+	}
+	if recv == "" {
+		return errors.New("method target must include a receiver type")
+	}
+
+	t.TypeName = recv
+	t.FuncName = fn
+	return nil
+}
+
+// setPattern compiles a glob or "re:" pattern target.
+func (t *Target) setPattern(s string) error {
+	expr, ok := strings.CutPrefix(s, "re:")
+	if !ok {
+		expr = globToRegexp(s)
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return errors.Wrap(err, "invalid target pattern")
+	}
+
+	t.Pattern = s
+	t.pattern = re
+	return nil
+}
+
+// globToRegexp translates a glob over a qualified handler name. The part
+// after the last "." is the function name, where * stops at "."; before it,
+// * matches any run of characters, including "/" and ".". A glob without a
+// package part matches the name in any package.
+func globToRegexp(glob string) string {
+	translate := func(s, star string) string {
+		var b strings.Builder
+		for _, r := range s {
+			switch r {
+			case '*':
+				b.WriteString(star)
+			case '?':
+				b.WriteString(".")
+			default:
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		return b.String()
+	}
+
+	idx := strings.LastIndex(glob, ".")
+	if idx == -1 {
+		return `^(.*\.)?` + translate(glob, `[^.]*`) + `$`
+	}
+	return `^` + translate(glob[:idx], `.*`) + `\.` + translate(glob[idx+1:], `[^.]*`) + `$`
+}
+
+// qualifiedName is the name pattern targets are matched against:
+// full/pkg/path.Func, or full/pkg/path.Type.Method for methods.
+func qualifiedName(fn *types.Func) string {
+	name := fn.Name()
+	if named := receiverNamed(fn); named != nil {
+		name = named.Obj().Name() + "." + name
+	}
+	if fn.Pkg() != nil {
+		name = fn.Pkg().Path() + "." + name
+	}
+	return name
+}
+
+// matchName checks an unresolved identifier or selector against the target.
+// kind describes the expression for the error message.
+func (t Target) matchName(name, kind string) error {
+	if t.pattern != nil {
+		return errors.Errorf("expected a handler matching '%s', got %s '%s'", t.Pattern, kind, name)
+	}
+	if name != t.FuncName {
+		return errors.Errorf("expected call '%s', got '%s'", t.FuncName, name)
+	}
+	if t.TypeName != "" {
+		return errors.Errorf("expected method of '%s', got %s", t.receiver(), kind)
+	}
+	if t.PackagePath != "" {
+		return errors.Errorf("expected package '%s', got %s", t.PackagePath, kind)
+	}
+	return nil
+}
+
+// matchForm checks that a handler is deferred the way the target expects:
+// factories are called and their result deferred, other handlers are
+// deferred directly.
+func (t Target) matchForm(factory bool) error {
+	if t.Factory && !factory {
+		return errors.Errorf("'%s' is a handler factory, defer the function it returns: defer %s(...)()", t, t.FuncName)
+	}
+	if !t.Factory && factory {
+		return errors.Errorf("'%s' is not a handler factory, defer it directly", t)
+	}
+	return nil
+}
+
+// typeResolver looks up the declared type of a method target's receiver,
+// starting from the given package. It returns nil if the type is unknown.
+type typeResolver func(t Target, from *types.Package) types.Type
+
+func (t Target) matchFunc(fn *types.Func, resolve typeResolver) error {
+	if t.pattern != nil {
+		if name := qualifiedName(fn); !t.pattern.MatchString(name) {
+			return errors.Errorf("expected a handler matching '%s', got '%s'", t.Pattern, name)
+		}
+		return nil
+	}
+	if fn.Name() != t.FuncName {
+		return errors.Errorf("expected call '%s', got '%s'", t.FuncName, fn.Name())
+	}
+	if t.TypeName != "" {
+		return t.matchReceiver(fn, resolve)
+	}
+	if t.PackagePath == "" {
+		return nil
+	}
+
+	pkgPath := ""
+	if fn.Pkg() != nil {
+		pkgPath = fn.Pkg().Path()
+	}
+	if pkgPath == t.PackagePath {
+		return nil
+	}
+	return errors.Errorf("expected package '%s', got '%s'", t.PackagePath, pkgPath)
+}
+
+func (t Target) matchReceiver(fn *types.Func, resolve typeResolver) error {
+	named := receiverNamed(fn)
+	if named == nil {
+		return errors.Errorf("expected method of '%s', got function '%s'", t.receiver(), fn.FullName())
+	}
+
+	obj := named.Obj()
+	pkgPath := ""
+	if obj.Pkg() != nil {
+		pkgPath = obj.Pkg().Path()
+	}
+	if obj.Name() == t.TypeName && (t.PackagePath == "" || pkgPath == t.PackagePath) {
+		return nil
+	}
+
+	// The call goes through another type; accept it if the target is an
+	// interface that type implements.
+	if resolve != nil {
+		if typ := resolve(t, fn.Pkg()); typ != nil {
+			if iface, ok := typ.Underlying().(*types.Interface); ok && implementsByName(named, iface) {
+				return nil
+			}
+		}
+	}
+	return errors.Errorf("expected method of '%s', got '%s'", t.receiver(), fn.FullName())
+}
+
+// implementsByName reports whether typ has every method of iface. Methods are
+// compared by name and signature string rather than with types.Implements,
+// because iface may come from a separately loaded copy of its package.
+func implementsByName(typ types.Type, iface *types.Interface) bool {
+	if !types.IsInterface(typ) {
+		typ = types.NewPointer(typ)
+	}
+	mset := types.NewMethodSet(typ)
+	qualifier := func(p *types.Package) string { return p.Path() }
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		want := iface.Method(i)
+		sel := mset.Lookup(want.Pkg(), want.Name())
+		if sel == nil {
+			return false
+		}
+		if types.TypeString(sel.Type(), qualifier) != types.TypeString(want.Type(), qualifier) {
+			return false
+		}
+	}
+	return true
+}
+
+// receiverNamed returns the named receiver type of a method, looking through
+// pointers and aliases, or nil for plain functions.
+func receiverNamed(fn *types.Func) *types.Named {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return nil
+	}
+
+	recv := types.Unalias(sig.Recv().Type())
+	if ptr, ok := recv.(*types.Pointer); ok {
+		recv = types.Unalias(ptr.Elem())
+	}
+	named, _ := recv.(*types.Named)
+	return named
+}
+
+// Targets is a set of accepted panic handlers; deferring any one of them
+// guards a goroutine.
+type Targets []Target
+
+func (t Targets) String() string {
+	names := make([]string, 0, len(t))
+	for _, target := range t {
+		names = append(names, target.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Set adds every comma-separated target in s.
+func (t *Targets) Set(s string) error {
+	for _, item := range splitList(s) {
+		var target Target
+		if err := target.Set(strings.TrimSpace(item)); err != nil {
+			return errors.Wrapf(err, "invalid target '%s'", item)
+		}
+		*t = append(*t, target)
+	}
+	return nil
+}
+
+// Description names the accepted handlers for diagnostics.
+func (t Targets) Description() string {
+	if len(t) == 1 {
+		return t[0].String()
+	}
+	names := make([]string, 0, len(t))
+	for _, target := range t {
+		names = append(names, target.String())
+	}
+	return "one of " + strings.Join(names, ", ")
+}
+
+// splitList splits a comma-separated flag value. Commas inside brackets,
+// braces or parentheses do not separate items, so a re: quantifier such as
+// {1,2} or a character class [,;] stays within its pattern. Characters
+// escaped with a backslash, as in re:a\(, neither open nor close a group.
+func splitList(s string) []string {
+	var items []string
+	depth, start := 0, 0
+	escaped := false
+	for i, r := range s {
+		if escaped {
+			escaped = false
+			continue
+		}
+		switch r {
+		case '\\':
+			escaped = true
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, s[start:])
+}
+
+// matchName checks an unresolved name; factory tells whether the deferred
+// function is the result of calling it.
+func (t Targets) matchName(name, kind string, factory bool) error {
+	return t.match(name, factory, func(target Target) error { return target.matchName(name, kind) })
+}
+
+func (t Targets) matchFunc(fn *types.Func, factory bool, resolve typeResolver) error {
+	return t.match(fn.FullName(), factory, func(target Target) error { return target.matchFunc(fn, resolve) })
+}
+
+func (t Targets) match(got string, factory bool, match func(Target) error) error {
+	var err, formErr error
+	for _, target := range t {
+		if err = match(target); err == nil {
+			if formErr = target.matchForm(factory); formErr == nil {
+				return nil
+			}
+		}
+	}
+	if formErr != nil {
+		// the right handler, deferred the wrong way
+		return formErr
+	}
+	if len(t) == 1 {
+		return err
+	}
+	return errors.Errorf("expected one of '%s', got '%s'", t.String(), got)
+}
+
+// targetsFlag is the flag.Value behind a list of targets. The first value
+// given on the command line replaces the defaults, later ones are added.
+type targetsFlag struct {
+	targets *Targets
+	set     bool
+}
+
+func (f *targetsFlag) String() string {
+	if f.targets == nil {
+		return ""
+	}
+	return f.targets.String()
+}
+
+func (f *targetsFlag) Set(s string) error {
+	if !f.set {
+		*f.targets = nil
+		f.set = true
+	}
+	return f.targets.Set(s)
+}
@@ -0,0 +1,82 @@
+package bypass
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func fatal() {
+	go func() {
+		defer HandlePanic()
+		if len(os.Args) == 0 {
+			log.Fatalf("no arguments") // want "call to log.Fatalf bypasses the handler: it terminates without a panic for the handler to recover"
+		}
+	}()
+}
+
+func exit() {
+	go func() {
+		defer HandlePanic()
+		os.Exit(1) // want "call to os.Exit bypasses the handler"
+	}()
+}
+
+func loggerFatal(logger *log.Logger) {
+	go func() {
+		defer HandlePanic()
+		logger.Fatalln("stopping") // want "call to \\(\\*log.Logger\\).Fatalln bypasses the handler"
+	}()
+}
+
+func goexit() {
+	go func() {
+		defer HandlePanic()
+		runtime.Goexit() // want "call to runtime.Goexit bypasses the handler"
+	}()
+}
+
+func mustLoad(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(data)
+}
+
+func loadConfig() string {
+	return mustLoad("config.yaml")
+}
+
+func worker() {
+	defer HandlePanic()
+	fmt.Println(loadConfig()) // want "call to log.Fatal bypasses the handler: it terminates without a panic for the handler to recover \\(call chain: loadConfig -> mustLoad -> log.Fatal\\)"
+}
+
+func startWorker() {
+	go worker()
+	go worker()
+}
+
+func unguardedIsNotChecked() {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		os.Exit(1)
+	}()
+}
+
+func nestedGoroutine() {
+	go func() {
+		defer HandlePanic()
+		go func() {
+			defer HandlePanic()
+			fmt.Println("Hello, World!")
+		}()
+	}()
+}
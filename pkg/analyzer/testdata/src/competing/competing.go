@@ -0,0 +1,81 @@
+package competing
+
+import (
+	"fmt"
+	"sync"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func Report(r any) {
+	fmt.Println("reported:", r)
+}
+
+func inlineAfterHandler() {
+	go func() {
+		defer HandlePanic()
+		defer func() {
+			recover() // want "recover\\(\\) in a function deferred after the handler runs first and swallows the panic, so HandlePanic never sees it; pass the recovered value to competing.Report"
+		}()
+		fmt.Println("work")
+	}()
+}
+
+func forwarded() {
+	go func() {
+		defer HandlePanic()
+		defer func() {
+			if r := recover(); r != nil {
+				Report(r)
+			}
+		}()
+		fmt.Println("work")
+	}()
+}
+
+func swallow() {
+	if r := recover(); r != nil { // want "recover\\(\\) in a function deferred after the handler runs first and swallows the panic"
+		fmt.Println("ignored:", r)
+	}
+}
+
+func namedAfterHandler() {
+	go func() {
+		defer HandlePanic()
+		defer swallow()
+		fmt.Println("work")
+	}()
+}
+
+func step(mu *sync.Mutex) {
+	mu.Lock()
+	defer mu.Unlock()
+	defer func() {
+		recover() // want "recover\\(\\) deferred in step, which the guarded goroutine calls, swallows the panic, so HandlePanic never sees it"
+	}()
+	fmt.Println("step")
+}
+
+func helper(mu *sync.Mutex) {
+	go func() {
+		defer HandlePanic()
+		step(mu)
+	}()
+}
+
+func reportingStep() {
+	defer func() {
+		Report(recover())
+	}()
+}
+
+func reportingHelper() {
+	go func() {
+		defer HandlePanic()
+		reportingStep()
+	}()
+}
@@ -0,0 +1,144 @@
+package deadguard // want package:"guards deadguard.Exported"
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type runner interface {
+	run()
+}
+
+type service struct{}
+
+func (s *service) run() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func worker() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func trampolined(id int) {
+	defer HandlePanic()
+	fmt.Println(id)
+}
+
+func passedAsValue() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+var callbacks []func()
+
+func start(r runner) {
+	go worker()
+	go func() {
+		trampolined(1)
+	}()
+	go r.run()
+	callbacks = append(callbacks, passedAsValue)
+}
+
+func synchronous() {
+	defer HandlePanic() // want "HandlePanic deferred in synchronous, which is never started as a goroutine: called synchronously it swallows panics its callers should see"
+	fmt.Println("Hello, World!")
+}
+
+func (s *service) load() {
+	defer HandlePanic() // want "HandlePanic deferred in \\(\\*service\\).load, which is never started as a goroutine"
+	fmt.Println("Hello, World!")
+}
+
+func callSync(s *service) {
+	synchronous()
+	s.load()
+}
+
+func step() {
+	defer HandlePanic() // want "HandlePanic deferred in step is nested inside a guarded caller: it swallows panics before the caller's handler sees them"
+	fmt.Println("Hello, World!")
+}
+
+func loop() {
+	defer HandlePanic()
+	for i := 0; i < 3; i++ {
+		step()
+	}
+}
+
+func startLoop() {
+	go loop()
+	go step()
+}
+
+// Exported functions may be started by other packages.
+func Exported() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func main() {
+	defer HandlePanic()
+	callSync(&service{})
+}
+
+type ticker struct {
+	onTick func()
+}
+
+type hooks struct {
+	onStop func()
+}
+
+func tick() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func stopHook() {
+	defer HandlePanic() // want "HandlePanic deferred in stopHook, which is never started as a goroutine"
+	fmt.Println("Hello, World!")
+}
+
+func spawned() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func visited() {
+	defer HandlePanic() // want "HandlePanic deferred in visited, which is never started as a goroutine"
+	fmt.Println("Hello, World!")
+}
+
+func assigned() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func spawn(f func()) {
+	go f()
+}
+
+func visit(f func()) {
+	f()
+}
+
+func startHolders() {
+	t := &ticker{onTick: tick}
+	go t.onTick()
+
+	h := hooks{onStop: stopHook}
+	h.onStop()
+
+	spawn(spawned)
+	visit(visited)
+
+	run := assigned
+	go run()
+}
@@ -0,0 +1,23 @@
+package main // want "HandlePanic deferred in deadguardmodule/lib.Load, which the program never starts as a goroutine: called synchronously it swallows panics its callers should see"
+
+import (
+	"deadguardmodule/lib"
+)
+
+type runner interface {
+	Run()
+}
+
+func main() {
+	lib.Load()
+	go lib.Poll()
+
+	var r runner = &lib.Service{}
+	go r.Run()
+	(&lib.Service{}).Run()
+
+	go func() {
+		defer lib.HandlePanic()
+		lib.Nested() // want "HandlePanic deferred in deadguardmodule/lib.Nested is nested inside a guarded caller: it swallows panics before the caller's handler sees them"
+	}()
+}
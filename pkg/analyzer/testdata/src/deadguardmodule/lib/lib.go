@@ -0,0 +1,36 @@
+package lib
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func Load() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func Poll() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func Nested() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+func Unused() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
+
+type Service struct{}
+
+func (s *Service) Run() {
+	defer HandlePanic()
+	fmt.Println("Hello, World!")
+}
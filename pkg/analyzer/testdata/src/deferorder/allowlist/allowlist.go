@@ -0,0 +1,78 @@
+package allowlist
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func handlerFirst(conn net.Conn) {
+	go func() {
+		defer HandlePanic()
+		defer conn.Close()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func allowedBefore() {
+	var wg sync.WaitGroup
+	go func() {
+		defer wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func closeBefore(conn net.Conn) {
+	go func() {
+		defer conn.Close() // want "defer conn.Close\\(\\) is registered before the handler, so it runs after the handler has returned and a panic inside it is not recovered"
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func severalBefore(conn net.Conn, mu *sync.Mutex) {
+	var wg sync.WaitGroup
+	go func() {
+		defer wg.Done()
+		defer mu.Unlock()  // want "defer mu.Unlock\\(\\) is registered before the handler"
+		defer conn.Close() // want "defer conn.Close\\(\\) is registered before the handler"
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func noHandler() {
+	var wg sync.WaitGroup
+	go func() { // want "missing defer call to HandlePanic"
+		defer wg.Done()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func release(id int) {
+	fmt.Println("released", id)
+}
+
+func allowedArguments(id int) {
+	go func() {
+		defer release(1)
+		defer release(id)
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func allowedNilReceiver(wg *sync.WaitGroup) {
+	go func() {
+		defer wg.Done() // want "defer wg.Done\\(\\) is registered before the handler and may panic: receiver wg may be nil"
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,30 @@
+package strict
+
+import (
+	"fmt"
+	"sync"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func handlerFirst() {
+	var wg sync.WaitGroup
+	go func() {
+		defer HandlePanic()
+		defer wg.Done()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func waitGroupBefore() {
+	var wg sync.WaitGroup
+	go func() {
+		defer wg.Done() // want "the handler must be the first defer registered: defers run in reverse order, so defer wg.Done\\(\\) runs after the handler has returned"
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
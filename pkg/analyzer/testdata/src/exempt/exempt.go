@@ -0,0 +1,274 @@
+package exempt
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type tracker struct {
+	done  atomic.Bool
+	count atomic.Int64
+	ch    chan int
+}
+
+func signal() {
+	ch := make(chan struct{})
+	go func() {
+		ch <- struct{}{}
+	}()
+	<-ch
+}
+
+func storeFlag() {
+	var done atomic.Bool
+	go func() {
+		done.Store(true)
+	}()
+}
+
+func (t *tracker) markDone() {
+	go func() {
+		t.done.Store(true)
+		t.count.Add(1)
+	}()
+}
+
+var started atomic.Int64
+
+func bump(n int64) {
+	for i := int64(0); i < n; i++ {
+		started.Add(1)
+	}
+}
+
+func helperCall() {
+	go func() {
+		bump(3)
+	}()
+}
+
+func bumpCounter(counter *atomic.Int64) {
+	counter.Add(1)
+}
+
+func helperWithPointer() {
+	counter := new(atomic.Int64)
+	go func() { // want "missing defer call to HandlePanic: trampoline callee bumpCounter: first statement is not defer"
+		// the helper cannot rely on its caller passing a non-nil pointer
+		bumpCounter(counter)
+	}()
+}
+
+func sum(values [3]int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func startSum() {
+	go sum([3]int{1, 2, 3})
+}
+
+func closedChannel() {
+	results := make(chan int)
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		results <- 1
+	}()
+	close(results)
+}
+
+func parameterChannel(ch chan int) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		ch <- 1
+	}()
+}
+
+func fieldChannel(t *tracker) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		t.ch <- 1
+	}()
+}
+
+func nilPointer(t *tracker) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		t.done.Store(true)
+	}()
+}
+
+func unknownCall() {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func index(values []int) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		_ = values[0]
+	}()
+}
+
+func divide(a, b int) int {
+	return a / b
+}
+
+func startDivide() {
+	go divide(1, 2) // want "missing defer call to HandlePanic: first statement is not defer"
+}
+
+func recursive(n int) {
+	if n > 0 {
+		recursive(n - 1)
+	}
+}
+
+func startRecursive() {
+	go recursive(3) // want "missing defer call to HandlePanic: first statement is not defer"
+}
+
+type settings struct {
+	n int
+}
+
+var (
+	current  = &settings{}
+	defaults = &settings{n: 1}
+)
+
+func resetCurrent() {
+	current = nil
+}
+
+func readCurrent() {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		// current is set to nil in resetCurrent
+		_ = current.n
+	}()
+}
+
+func readDefaults() {
+	go func() {
+		_ = defaults.n
+	}()
+}
+
+func addThroughPointer(counter *int64) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		atomic.AddInt64(counter, 1)
+	}()
+}
+
+func addToLocal() {
+	var counter int64
+	go func() {
+		atomic.AddInt64(&counter, 1)
+	}()
+}
+
+func storeValue(v *atomic.Value) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		// atomic.Value panics on nil or inconsistently typed values
+		v.Store(nil)
+	}()
+}
+
+func aliasedChannel() {
+	ch := make(chan int, 1)
+	done := ch
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		ch <- 1
+	}()
+	close(done)
+}
+
+func bufferedChannel() {
+	ch := make(chan int, 1)
+	go func() {
+		ch <- len(ch)
+	}()
+	<-ch
+}
+
+func arrayPointer(values []int) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		_ = (*[2]int)(values)
+	}()
+}
+
+func compareInterfaces(a, b any) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		_ = a == b
+	}()
+}
+
+func compareNil(a any) {
+	go func() {
+		_ = a == nil
+	}()
+}
+
+func switchInterface(a any) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		switch a {
+		case 1:
+		}
+	}()
+}
+
+func makeMap(size int) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		_ = make(map[string]int, size)
+	}()
+}
+
+type inner struct {
+	n int
+}
+
+func (i *inner) bump() {
+	i.n++
+}
+
+type outer struct {
+	*inner
+}
+
+type byValue struct {
+	inner
+}
+
+func embeddedField() {
+	o := outer{}
+	var counter atomic.Int64
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		// o.inner is nil
+		counter.Store(int64(o.n))
+	}()
+}
+
+func embeddedMethod() {
+	o := outer{}
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: embedded inner of o may be nil"
+		o.bump()
+	}()
+}
+
+func embeddedValueMethod() {
+	var b byValue
+	go func() {
+		b.bump()
+	}()
+}
+
+func methodExpression(i *inner) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: receiver i may be nil"
+		(*inner).bump(i)
+	}()
+}
@@ -0,0 +1,34 @@
+package factory
+
+import (
+	"fmt"
+
+	"factory/guard"
+)
+
+func goodFactory() {
+	go func() {
+		defer guard.Recover("worker")()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodMethodFactory(g *guard.Guard) {
+	go func() {
+		defer g.Recover("worker")()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badFactoryNotCalled() {
+	go func() { // want "'factory/guard.Recover\\(\\)' is a handler factory, defer the function it returns"
+		defer guard.Recover("worker")
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badAnonymous() {
+	go func() { // want "missing defer call to one of factory/guard.Recover\\(\\), \\(\\*factory/guard.Guard\\).Recover\\(\\)"
+		fmt.Println("Hello, World!")
+	}()
+}
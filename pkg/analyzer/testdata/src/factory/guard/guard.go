@@ -0,0 +1,15 @@
+package guard
+
+func Recover(name string) func() {
+	return func() {
+		recover()
+	}
+}
+
+type Guard struct{}
+
+func (g *Guard) Recover(name string) func() {
+	return func() {
+		recover()
+	}
+}
@@ -0,0 +1,122 @@
+package fields
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type Server struct {
+	onEvent func()
+	onStop  func()
+	name    string
+}
+
+func guardedEvent() {
+	defer HandlePanic()
+	fmt.Println("event")
+}
+
+func unguardedEvent() {
+	fmt.Println("event")
+}
+
+func keyed() {
+	s := Server{onEvent: guardedEvent}
+	go s.onEvent()
+}
+
+func positional() {
+	s := &Server{func() {
+		defer HandlePanic()
+		fmt.Println("event")
+	}, nil, "positional"}
+	go s.onEvent()
+}
+
+type Client struct {
+	onMessage func()
+}
+
+func assigned() {
+	var c Client
+	c.onMessage = func() {
+		defer HandlePanic()
+		fmt.Println("message")
+	}
+	go c.onMessage()
+}
+
+type Worker struct {
+	run func()
+}
+
+func (w *Worker) setRun(f func()) {
+	w.run = f
+}
+
+func reassigned() {
+	w := &Worker{run: guardedEvent}
+	w.setRun(unguardedEvent)
+	go w.run() // want "missing defer call to HandlePanic: field run set to unguardedEvent: first statement is not defer"
+}
+
+type Notifier struct {
+	notify func()
+}
+
+func NewNotifier(notify func()) *Notifier {
+	return &Notifier{notify: notify}
+}
+
+func (n *Notifier) start() {
+	go n.notify() // want "missing defer call to HandlePanic: field notify set to unguardedEvent: first statement is not defer"
+}
+
+func constructors() {
+	NewNotifier(guardedEvent).start()
+	NewNotifier(unguardedEvent).start()
+}
+
+type Hooks struct {
+	onClose func()
+}
+
+func unassigned(h Hooks) {
+	go h.onClose()
+}
+
+func stopping(s Server) {
+	s.onStop = nil
+	s.onStop = s.onEvent
+	go s.onStop()
+}
+
+type swapper struct {
+	a func()
+	b func()
+}
+
+func swapped(s *swapper) {
+	s.a = s.b
+	s.b = s.a
+	go s.a() // want "missing defer call to HandlePanic: field a set to s.b: field b set to unguardedEvent: first statement is not defer"
+}
+
+func swappedUnguarded(s *swapper) {
+	s.b = unguardedEvent
+	go s.b() // want "missing defer call to HandlePanic: field b set to unguardedEvent: first statement is not defer"
+}
+
+type cycle struct {
+	c func()
+	d func()
+}
+
+func cycled(s *cycle) {
+	s.c = s.d
+	s.d = s.c
+	go s.c()
+}
@@ -0,0 +1,171 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type config struct {
+	name    string
+	retries int
+}
+
+func localSetup() {
+	go func() {
+		const retries = 3
+		name := "worker"
+		attempts := 0
+		defer HandlePanic()
+		fmt.Println(name, attempts, retries)
+	}()
+}
+
+func earlyReturn(done bool) {
+	go func() {
+		if done {
+			return
+		}
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func structSetup() {
+	go func() {
+		cfg := config{name: "worker"}
+		cfg.retries = 2
+		defer HandlePanic()
+		fmt.Println(cfg)
+	}()
+}
+
+func worker(id int) {
+	next := id + 1
+	defer HandlePanic()
+	fmt.Println(next)
+}
+
+func startWorker() {
+	go worker(1)
+}
+
+func callBeforeHandler(ctx context.Context) {
+	go func() { // want "call to \\(context.Context\\).Err may panic before the handler is deferred"
+		if ctx.Err() != nil {
+			return
+		}
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func indexBeforeHandler(ids []int) {
+	go func() { // want "index expression may panic before the handler is deferred"
+		id := ids[0]
+		defer HandlePanic()
+		fmt.Println(id)
+	}()
+}
+
+func mapWriteBeforeHandler(seen map[string]bool) {
+	go func() { // want "map update may panic before the handler is deferred"
+		seen["worker"] = true
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func derefBeforeHandler(cfg *config) {
+	go func() { // want "dereference of a computed value may panic before the handler is deferred"
+		name := cfg.name
+		defer HandlePanic()
+		fmt.Println(name)
+	}()
+}
+
+func conversionBeforeHandler(value any) {
+	go func() { // want "type assertion may panic before the handler is deferred"
+		name := value.(string)
+		defer HandlePanic()
+		fmt.Println(name)
+	}()
+}
+
+func divide(total, parts int) {
+	share := total / parts
+	defer HandlePanic()
+	fmt.Println(share)
+}
+
+func startDivide() {
+	go divide(10, 2) // want "integer division may panic before the handler is deferred"
+}
+
+func handlerOnOneBranch(verbose bool) {
+	go func() { // want "call to fmt.Println may panic before the handler is deferred"
+		if verbose {
+			defer HandlePanic()
+		}
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func missingHandler() {
+	go func() { // want "no defer statement"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func compareBeforeHandler(a, b any) {
+	go func() { // want "comparison of interface values may panic before the handler is deferred"
+		same := a == b
+		defer HandlePanic()
+		fmt.Println(same)
+	}()
+}
+
+func compareNilBeforeHandler(err error) {
+	go func() {
+		failed := err != nil
+		defer HandlePanic()
+		fmt.Println(failed)
+	}()
+}
+
+func lookupBeforeHandler(seen map[any]bool, key any) {
+	go func() { // want "map lookup with an interface key may panic before the handler is deferred"
+		ok := seen[key]
+		defer HandlePanic()
+		fmt.Println(ok)
+	}()
+}
+
+func lookupStringBeforeHandler(seen map[string]bool) {
+	go func() {
+		ok := seen["worker"]
+		defer HandlePanic()
+		fmt.Println(ok)
+	}()
+}
+
+func makeMapBeforeHandler(size int) {
+	go func() { // want "make may panic before the handler is deferred"
+		seen := make(map[string]bool, size)
+		defer HandlePanic()
+		fmt.Println(len(seen))
+	}()
+}
+
+func makeConstantMapBeforeHandler() {
+	go func() {
+		seen := make(map[string]bool, 8)
+		defer HandlePanic()
+		fmt.Println(len(seen))
+	}()
+}
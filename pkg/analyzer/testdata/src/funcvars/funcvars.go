@@ -0,0 +1,59 @@
+package funcvars
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func goodWorker() {
+	defer HandlePanic()
+	fmt.Println("good")
+}
+
+func badWorker() {
+	fmt.Println("bad")
+}
+
+type Example struct{}
+
+func (e *Example) goodMethod() {
+	defer HandlePanic()
+	fmt.Println("good method")
+}
+
+func (e *Example) badMethod() {
+	fmt.Println("bad method")
+}
+
+func namedFunction() {
+	f := goodWorker
+	go f()
+
+	g := badWorker
+	go g() // want "missing defer call to HandlePanic: g set to badWorker: first statement is not defer"
+}
+
+func methodValue(e *Example) {
+	h := e.goodMethod
+	go h()
+
+	b := e.badMethod
+	go b() // want "missing defer call to HandlePanic: b set to e.badMethod: first statement is not defer"
+}
+
+func reassigned(cond bool) {
+	f := goodWorker
+	if cond {
+		f = badWorker
+	}
+	go f() // want "missing defer call to HandlePanic: f set to badWorker: first statement is not defer"
+}
+
+func packageLevel() {
+	go startHook()
+	go stopHook() // want "missing defer call to HandlePanic: stopHook set to badWorker: first statement is not defer"
+	go methodHook()
+}
@@ -0,0 +1,7 @@
+package funcvars
+
+var (
+	startHook  = goodWorker
+	stopHook   = badWorker
+	methodHook = (&Example{}).goodMethod
+)
@@ -0,0 +1,62 @@
+package generics
+
+import "fmt"
+
+// HandlePanic is a generic handler that reports the panic with a typed tag.
+func HandlePanic[T any]() {
+	if r := recover(); r != nil {
+		var tag T
+		fmt.Println("recovered:", tag, r)
+	}
+}
+
+func process[T any](value T) {
+	defer HandlePanic[string]()
+	fmt.Println(value)
+}
+
+func unguarded[T any](value T) {
+	fmt.Println(value)
+}
+
+func pair[K comparable, V any](key K, value V) {
+	defer HandlePanic[K]()
+	fmt.Println(key, value)
+}
+
+type Server[T any] struct {
+	items []T
+}
+
+func (s *Server[T]) run() {
+	defer HandlePanic[T]()
+	fmt.Println(s.items)
+}
+
+func (s *Server[T]) stop() {
+	fmt.Println(len(s.items))
+}
+
+func (s Server[T]) serve() {
+	defer HandlePanic[int]()
+	fmt.Println(s.items)
+}
+
+func start() {
+	go process[int](1)
+	go process(2)
+	go unguarded[int](3) // want "missing defer call to generics.HandlePanic: first statement is not defer"
+	go pair[string, int]("a", 1)
+
+	s := &Server[int]{}
+	go s.run()
+	go s.stop() // want "missing defer call to generics.HandlePanic: first statement is not defer"
+
+	var v Server[string]
+	go v.serve()
+
+	go func() {
+		defer HandlePanic[error]()
+		fmt.Println("literal")
+	}()
+}
@@ -0,0 +1,14 @@
+package discarded
+
+import "fmt"
+
+func HandlePanic() {
+	recover() // want "configured handler HandlePanic discards the recovered value without reporting it"
+}
+
+func worker() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
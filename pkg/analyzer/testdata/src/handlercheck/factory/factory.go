@@ -0,0 +1,24 @@
+package factory
+
+import "fmt"
+
+func Recover(name string) func() {
+	return func() {
+		if r := recover(); r != nil {
+			fmt.Println(name, "recovered:", r)
+		}
+	}
+}
+
+func Guard(name string) func() { // want "configured handler Guard\\(\\) never calls recover\\(\\), so it cannot stop a panic"
+	return func() {
+		fmt.Println(name, "done")
+	}
+}
+
+func worker() {
+	go func() {
+		defer Recover("worker")()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,19 @@
+package nested
+
+import "fmt"
+
+func HandlePanic() { // want "configured handler HandlePanic calls recover\\(\\) only from a nested function, where it always returns nil"
+	report := func() {
+		if r := recover(); r != nil {
+			fmt.Println("recovered:", r)
+		}
+	}
+	report()
+}
+
+func worker() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
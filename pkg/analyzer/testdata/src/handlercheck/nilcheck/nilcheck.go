@@ -0,0 +1,16 @@
+package nilcheck
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil { // want "configured handler HandlePanic discards the recovered value without reporting it"
+		fmt.Println("something panicked")
+	}
+}
+
+func worker() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,14 @@
+package norecover
+
+import "fmt"
+
+func HandlePanic() { // want "configured handler HandlePanic never calls recover\\(\\), so it cannot stop a panic"
+	fmt.Println("cleaning up")
+}
+
+func worker() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,7 @@
+package handler
+
+import "fmt"
+
+func HandlePanic() {
+	fmt.Println("cleaning up")
+}
@@ -0,0 +1,21 @@
+package remote
+
+import (
+	"fmt"
+
+	"handlercheck/remote/handler"
+)
+
+func worker() {
+	go func() { // want "configured handler handlercheck/remote/handler.HandlePanic never calls recover\\(\\), so it cannot stop a panic"
+		defer handler.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func otherWorker() {
+	go func() {
+		defer handler.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,71 @@
+package ifacetarget
+
+import (
+	"fmt"
+
+	"ifacetarget/obs"
+)
+
+// logHandler implements obs.PanicHandler without naming it.
+type logHandler struct{}
+
+func (logHandler) Handle() {}
+
+// labelHandler has a Handle method with the wrong signature.
+type labelHandler struct{}
+
+func (labelHandler) Handle(label string) {}
+
+type recoverer interface {
+	Handle()
+}
+
+type service struct {
+	panics obs.PanicHandler
+	local  recoverer
+	sentry *obs.Sentry
+	log    logHandler
+	label  labelHandler
+}
+
+func (s *service) goodInterface() {
+	go func() {
+		defer s.panics.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) goodLocalInterface() {
+	go func() {
+		defer s.local.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) goodImplementation() {
+	go func() {
+		defer s.sentry.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) goodImplicitImplementation() {
+	go func() {
+		defer s.log.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) badSignature() {
+	go func() { // want "missing defer call to \\(ifacetarget/obs.PanicHandler\\).Handle"
+		defer s.label.Handle("worker")
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badFunction() {
+	go func() { // want "expected method of 'ifacetarget/obs.PanicHandler', got function 'ifacetarget/obs.Handle'"
+		defer obs.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,11 @@
+package obs
+
+type PanicHandler interface {
+	Handle()
+}
+
+type Sentry struct{}
+
+func (s *Sentry) Handle() {}
+
+func Handle() {}
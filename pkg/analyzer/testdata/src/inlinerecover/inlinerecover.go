@@ -0,0 +1,96 @@
+package inlinerecover
+
+import (
+	"fmt"
+
+	"inlinerecover/obs"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		obs.Report(r)
+	}
+}
+
+func goodHandler() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodIfInit() {
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				obs.Report(r)
+			}
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodEarlyReturn() {
+	go func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			fmt.Println("panic:", r)
+			obs.Report(fmt.Errorf("goroutine panicked: %v", r))
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodDirect() {
+	go func() {
+		defer func() { obs.Report(recover()) }()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badNoRecover() {
+	go func() { // want "missing defer call to HandlePanic: inline recover: deferred closure does not call recover\\(\\) directly"
+		defer func() {
+			fmt.Println("done")
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badWrongReporter() {
+	go func() { // want "inline recover: recovered value is not passed to inlinerecover/obs.Report on every path where it is non-nil"
+		defer func() {
+			if r := recover(); r != nil {
+				obs.Log(r)
+			}
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badConditionalReport(verbose bool) {
+	go func() { // want "inline recover: recovered value is not passed to inlinerecover/obs.Report on every path where it is non-nil"
+		defer func() {
+			if r := recover(); r != nil {
+				if verbose {
+					obs.Report(r)
+				}
+			}
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badDiscarded() {
+	go func() { // want "inline recover: recovered value is not passed"
+		defer func() {
+			if recover() != nil {
+				obs.Report("something panicked")
+			}
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
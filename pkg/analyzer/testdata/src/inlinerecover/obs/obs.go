@@ -0,0 +1,5 @@
+package obs
+
+func Report(v any) {}
+
+func Log(v any) {}
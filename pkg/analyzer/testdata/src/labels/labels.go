@@ -0,0 +1,60 @@
+package labels
+
+import "fmt"
+
+const syncLabel = "sync-loop"
+
+func HandlePanic(name string) {
+	if r := recover(); r != nil {
+		fmt.Println(name, "recovered:", r)
+	}
+}
+
+func goodLabels() {
+	go func() {
+		defer HandlePanic("event-loop")
+		fmt.Println("Hello, World!")
+	}()
+
+	go func() {
+		defer HandlePanic(syncLabel)
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func worker() {
+	defer HandlePanic("worker")
+	fmt.Println("Hello, World!")
+}
+
+func startWorkers() {
+	// the same defer site started twice is not a duplicate
+	go worker()
+	go worker()
+}
+
+func duplicateLabels() {
+	go func() {
+		defer HandlePanic("poller") // want "goroutine label \"poller\" is not unique in the package"
+		fmt.Println("Hello, World!")
+	}()
+
+	go func() {
+		defer HandlePanic("poller") // want "goroutine label \"poller\" is not unique in the package"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func nonConstantLabel(name string) {
+	go func() {
+		defer HandlePanic(name) // want "goroutine label name is not a constant string"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badPattern() {
+	go func() {
+		defer HandlePanic("Bad Label") // want "goroutine label \"Bad Label\" does not match pattern '\\^\\[a-z\\]\\+\\(-\\[a-z\\]\\+\\)\\*\\$'"
+		fmt.Println("Hello, World!")
+	}()
+}
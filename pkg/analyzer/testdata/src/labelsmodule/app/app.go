@@ -0,0 +1,11 @@
+package app // want package:"labels first-only, poller, second-only" "goroutine label \"second-only\" is not unique in the module: imported packages use it 2 times"
+
+import (
+	"labelsmodule/other"
+	"labelsmodule/second"
+)
+
+func Run() {
+	second.Start()
+	other.Start()
+}
@@ -0,0 +1,21 @@
+package first
+
+import "fmt"
+
+func HandlePanic(name string) {
+	if r := recover(); r != nil {
+		fmt.Println(name, "recovered:", r)
+	}
+}
+
+func Start() {
+	go func() {
+		defer HandlePanic("poller")
+		fmt.Println("Hello, World!")
+	}()
+
+	go func() {
+		defer HandlePanic("first-only")
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,16 @@
+package other
+
+import "fmt"
+
+func HandlePanic(name string) {
+	if r := recover(); r != nil {
+		fmt.Println(name, "recovered:", r)
+	}
+}
+
+func Start() {
+	go func() {
+		defer HandlePanic("second-only")
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,21 @@
+package second // want package:"labels first-only, poller, second-only"
+
+import (
+	"fmt"
+
+	"labelsmodule/first"
+)
+
+func Start() {
+	first.Start()
+
+	go func() {
+		defer first.HandlePanic("poller") // want "goroutine label \"poller\" is not unique in the module"
+		fmt.Println("Hello, World!")
+	}()
+
+	go func() {
+		defer first.HandlePanic("second-only")
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,57 @@
+package methodtarget
+
+import (
+	"fmt"
+
+	"methodtarget/obs"
+)
+
+var reporter = &obs.Reporter{}
+
+type service struct {
+	obs    *obs.Reporter
+	tracer *obs.Tracer
+}
+
+func goodPackageVariable() {
+	go func() {
+		defer reporter.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodImportedVariable() {
+	go func() {
+		defer obs.Default.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) goodField() {
+	go func() {
+		defer s.obs.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodLocal() {
+	r := obs.Reporter{}
+	go func() {
+		defer r.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func (s *service) badReceiver() {
+	go func() { // want "missing defer call to \\(\\*methodtarget/obs.Reporter\\).Recover: target mismatch: expected method of '\\*methodtarget/obs.Reporter', got '\\(\\*methodtarget/obs.Tracer\\).Recover'"
+		defer s.tracer.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badFunction() {
+	go func() { // want "expected method of '\\*methodtarget/obs.Reporter', got function 'methodtarget/obs.Recover'"
+		defer obs.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
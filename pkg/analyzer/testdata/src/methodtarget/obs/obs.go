@@ -0,0 +1,13 @@
+package obs
+
+type Reporter struct{}
+
+func (r *Reporter) Recover() {}
+
+type Tracer struct{}
+
+func (t *Tracer) Recover() {}
+
+func Recover() {}
+
+var Default = &Reporter{}
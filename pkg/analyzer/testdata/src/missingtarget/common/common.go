@@ -0,0 +1,15 @@
+package common // want "configured handler missingtarget/common.HandlPanic does not exist: package missingtarget/common has no function HandlPanic \\(did you mean HandlePanic\\?\\)" "configured handler missingtarget/comon.HandlePanic does not exist: package missingtarget/comon not found \\(did you mean missingtarget/common\\?\\)" "configured handler \\(\\*missingtarget/common.Reporter\\).Recovr does not exist: type missingtarget/common.Reporter has no method Recovr \\(did you mean Recover\\?\\)"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		println(r)
+	}
+}
+
+type Reporter struct{}
+
+func (r *Reporter) Recover() {
+	if v := recover(); v != nil {
+		println(v)
+	}
+}
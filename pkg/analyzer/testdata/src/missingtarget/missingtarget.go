@@ -0,0 +1,21 @@
+package missingtarget
+
+import (
+	"fmt"
+
+	"missingtarget/common"
+)
+
+func worker() {
+	go func() { // want "missing defer call to one of"
+		defer common.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func otherWorker() {
+	go func() { // want "missing defer call to one of"
+		defer common.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,3 @@
+package common
+
+func HandlePanic() {}
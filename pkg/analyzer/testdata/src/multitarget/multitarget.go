@@ -0,0 +1,43 @@
+package multitarget
+
+import (
+	"fmt"
+
+	"multitarget/common"
+	"multitarget/sentryutil"
+	"multitarget/testutil"
+)
+
+func goodCommon() {
+	go func() {
+		defer common.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodSentry() {
+	go func() {
+		defer sentryutil.Recover()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodTestutil() {
+	go func() {
+		defer testutil.CatchPanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badAnonymous() {
+	go func() { // want "missing defer call to one of multitarget/common.HandlePanic, multitarget/sentryutil.Recover, multitarget/testutil.CatchPanic"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badWrongHandler() {
+	go func() { // want "expected one of .*, got 'multitarget/sentryutil.HandlePanic'"
+		defer sentryutil.HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,48 @@
+package nondeferred
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+// Recovered reports whether it stopped a panic.
+func Recovered() bool {
+	r := recover()
+	if r != nil {
+		fmt.Println("recovered:", r)
+	}
+	return r != nil
+}
+
+func plainCall() {
+	HandlePanic() // want "call to HandlePanic\\(\\) is not deferred: recover\\(\\) returns nil outside a deferred call, so it recovers nothing; use defer HandlePanic\\(\\)"
+	fmt.Println("work")
+}
+
+func inGoroutine() {
+	go func() {
+		defer HandlePanic()
+		fmt.Println("work")
+		HandlePanic() // want "call to HandlePanic\\(\\) is not deferred"
+	}()
+}
+
+func inExpression() {
+	if done := func() bool { HandlePanic(); return true }(); done { // want "call to HandlePanic\\(\\) is not deferred"
+		fmt.Println("done")
+	}
+}
+
+func inCondition() {
+	if Recovered() { // want "call to Recovered\\(\\) is not deferred"
+		fmt.Println("recovered")
+	}
+}
+
+func deferred() {
+	defer HandlePanic()
+	fmt.Println("work")
+}
@@ -0,0 +1,65 @@
+package params
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func spawn(f func()) {
+	go f()
+}
+
+func spawnNamed(name string, f func()) {
+	fmt.Println("starting", name)
+	go f()
+	go f()
+}
+
+func spawnVia(f func()) {
+	spawn(f)
+}
+
+func trampoline(f func()) {
+	go func() {
+		f()
+	}()
+}
+
+func goodWorker() {
+	defer HandlePanic()
+	fmt.Println("good")
+}
+
+func badWorker() {
+	fmt.Println("bad")
+}
+
+type Example struct{}
+
+func (e *Example) badMethod() {
+	fmt.Println("bad method")
+}
+
+func callers(e *Example) {
+	spawn(goodWorker)
+	spawn(badWorker) // want "missing defer call to HandlePanic: started as a goroutine by spawn: first statement is not defer"
+	spawn(func() {
+		defer HandlePanic()
+		fmt.Println("literal")
+	})
+	spawn(func() { // want "missing defer call to HandlePanic: started as a goroutine by spawn: first statement is not defer"
+		fmt.Println("literal")
+	})
+	spawnNamed("method", e.badMethod) // want "missing defer call to HandlePanic: started as a goroutine by spawnNamed: first statement is not defer"
+	spawnVia(badWorker)               // want "missing defer call to HandlePanic: started as a goroutine by spawn: first statement is not defer"
+	trampoline(goodWorker)
+	trampoline(badWorker) // want "missing defer call to HandlePanic: started as a goroutine by trampoline: first statement is not defer"
+}
+
+// Spawn is exported; callers in other packages are not visible here.
+func Spawn(f func()) {
+	go f()
+}
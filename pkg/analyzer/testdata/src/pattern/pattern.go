@@ -0,0 +1,37 @@
+package pattern
+
+import (
+	"fmt"
+
+	"pattern/legacy"
+	teama "pattern/teama/panics"
+	teamb "pattern/teamb/panics"
+)
+
+func goodTeamA() {
+	go func() {
+		defer teama.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodTeamB() {
+	go func() {
+		defer teamb.HandleWithSentry()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodMethod(h teamb.Handler) {
+	go func() {
+		defer h.HandleAll()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badLegacy() {
+	go func() { // want "missing defer call to one of \\*/panics.Handle\\*, \\*/panics.\\*.Handle\\*: target mismatch: expected one of .*, got 'pattern/legacy.Handle'"
+		defer legacy.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,7 @@
+package panics
+
+type Handler struct{}
+
+func (h Handler) HandleAll() {}
+
+func HandleWithSentry() {}
@@ -0,0 +1,142 @@
+package prologue
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/pprof"
+	"sync"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type service struct {
+	wg sync.WaitGroup
+}
+
+func (s *service) start() {
+	go func() {
+		defer s.wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func waitGroupValue() {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+	wg.Wait()
+}
+
+func waitGroupPointer() {
+	wg := &sync.WaitGroup{}
+	go func() {
+		defer wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func lockedThread() {
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func labelSetup(ctx context.Context) {
+	go func() {
+		ctx := pprof.WithLabels(ctx, pprof.Labels("worker", "poller"))
+		pprof.SetGoroutineLabels(ctx)
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func nilWaitGroup(wg *sync.WaitGroup) {
+	go func() { // want "statement before handler may panic: receiver wg may be nil"
+		defer wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func nilService(s *service) {
+	go func() { // want "statement before handler may panic: s may be nil"
+		defer s.wg.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func notAllowed(mu *sync.Mutex) {
+	go func() { // want "first statement is not defer"
+		mu.Lock()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func missingHandler() {
+	var wg sync.WaitGroup
+	go func() { // want "first statement after the allowed prologue is not defer"
+		defer wg.Done()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+var sharedWaitGroup = &sync.WaitGroup{}
+
+func stopShared() {
+	sharedWaitGroup = nil
+}
+
+func nilSharedWaitGroup() {
+	go func() { // want "statement before handler may panic: receiver sharedWaitGroup may be nil"
+		defer sharedWaitGroup.Done()
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+var labelled map[string]context.Context
+
+func nilMapTarget(ctx context.Context) {
+	go func() { // want "statement before handler may panic: map labelled may be nil"
+		labelled["worker"] = pprof.WithLabels(ctx, pprof.Labels("worker", "poller"))
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+type config struct {
+	ctx context.Context
+}
+
+func nilPointerTarget(ctx context.Context, c *config) {
+	go func() { // want "statement before handler may panic: c may be nil"
+		c.ctx = pprof.WithLabels(ctx, pprof.Labels("worker", "poller"))
+		defer HandlePanic()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func localTarget(ctx context.Context) {
+	c := &config{}
+	go func() {
+		c.ctx = pprof.WithLabels(ctx, pprof.Labels("worker", "poller"))
+		defer HandlePanic()
+		fmt.Println(c.ctx)
+	}()
+}
@@ -0,0 +1,30 @@
+package regexppattern
+
+import (
+	"fmt"
+
+	"pattern/legacy"
+	teama "pattern/teama/panics"
+	teamb "pattern/teamb/panics"
+)
+
+func goodTeamA() {
+	go func() {
+		defer teama.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badTeamB() {
+	go func() { // want "expected a handler matching 're:\\^pattern/team\\[ab\\]/panics\\\\.Handle\\$', got 'pattern/teamb/panics.HandleWithSentry'"
+		defer teamb.HandleWithSentry()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badLegacy() {
+	go func() { // want "got 'pattern/legacy.Handle'"
+		defer legacy.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,30 @@
+package regexpquantifier
+
+import (
+	"fmt"
+
+	"pattern/legacy"
+	teama "pattern/teama/panics"
+	teamb "pattern/teamb/panics"
+)
+
+func goodTeamA() {
+	go func() {
+		defer teama.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func goodLegacy() {
+	go func() {
+		defer legacy.Handle()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badTeamB() {
+	go func() { // want "got 'pattern/teamb/panics.HandleWithSentry'"
+		defer teamb.HandleWithSentry()
+		fmt.Println("Hello, World!")
+	}()
+}
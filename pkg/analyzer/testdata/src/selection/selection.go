@@ -0,0 +1,70 @@
+package selection
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type Example struct{}
+
+func (e Example) goodMethod() {
+	defer HandlePanic()
+	fmt.Println("value receiver")
+}
+
+func (e Example) badMethod() {
+	fmt.Println("value receiver")
+}
+
+func (e *Example) goodPointerMethod() {
+	defer HandlePanic()
+	fmt.Println("pointer receiver")
+}
+
+type Inner struct{}
+
+func (Inner) Method() {
+	defer HandlePanic()
+	fmt.Println("promoted")
+}
+
+func (*Inner) badPromoted() {
+	fmt.Println("promoted")
+}
+
+type Outer struct {
+	Inner
+	name string
+}
+
+type Deep struct {
+	*Outer
+}
+
+func pointerToValueReceiver() {
+	p := &Example{}
+	go p.goodMethod()
+	go p.badMethod() // want "missing defer call to HandlePanic: first statement is not defer"
+}
+
+func valueToPointerReceiver() {
+	var e Example
+	go e.goodPointerMethod()
+}
+
+func promoted() {
+	outer := Outer{name: "outer"}
+	go outer.Method()
+	go outer.badPromoted() // want "missing defer call to HandlePanic: first statement is not defer"
+
+	deep := Deep{Outer: &outer}
+	go deep.Method()
+}
+
+func methodExpression() {
+	go Example.goodMethod(Example{})
+	go (*Example).goodPointerMethod(&Example{})
+}
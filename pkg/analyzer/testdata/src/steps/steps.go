@@ -0,0 +1,83 @@
+package steps
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+
+	"steps/trace"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func allSteps(ctx context.Context) {
+	go func() {
+		defer HandlePanic()
+		pprof.SetGoroutineLabels(pprof.WithLabels(ctx, pprof.Labels("worker", "poller")))
+		ctx, span := trace.Start(ctx, "poller")
+		defer span.End()
+		fmt.Println(ctx)
+	}()
+}
+
+func worker(ctx context.Context) {
+	defer HandlePanic()
+	pprof.SetGoroutineLabels(ctx)
+	_, span := trace.Start(ctx, "worker")
+	defer span.End()
+	for i := 0; i < 3; i++ {
+		fmt.Println(i)
+	}
+}
+
+func startWorker(ctx context.Context) {
+	go worker(ctx)
+}
+
+func missingSpan(ctx context.Context) {
+	go func() { // want "goroutine does not start with required step 2 of 3: steps/trace.Start" "goroutine does not start with required step 3 of 3: defer \\(\\*steps/trace.Span\\).End"
+		defer HandlePanic()
+		pprof.SetGoroutineLabels(ctx)
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func outOfOrder(ctx context.Context) {
+	go func() {
+		defer HandlePanic()
+		_, span := trace.Start(ctx, "worker")
+		defer span.End()
+		pprof.SetGoroutineLabels(ctx) // want "required step runtime/pprof.SetGoroutineLabels must come before steps/trace.Start"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func notDeferred(ctx context.Context) {
+	go func() { // want "goroutine does not start with required step 3 of 3: defer \\(\\*steps/trace.Span\\).End"
+		defer HandlePanic()
+		pprof.SetGoroutineLabels(ctx)
+		_, span := trace.Start(ctx, "worker")
+		span.End()
+	}()
+}
+
+func afterControlFlow(ctx context.Context, enabled bool) {
+	go func() { // want "goroutine does not start with required step 1 of 3: runtime/pprof.SetGoroutineLabels"
+		defer HandlePanic()
+		_, span := trace.Start(ctx, "worker")
+		defer span.End()
+		if enabled {
+			pprof.SetGoroutineLabels(ctx)
+		}
+	}()
+}
+
+func missingHandlerAndSteps() {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer" "required step 1 of 3" "required step 2 of 3" "required step 3 of 3"
+		fmt.Println("Hello, World!")
+	}()
+}
@@ -0,0 +1,13 @@
+package trace
+
+import "context"
+
+type Span struct {
+	name string
+}
+
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return ctx, &Span{name: name}
+}
+
+func (s *Span) End() {}
@@ -0,0 +1,162 @@
+package trampoline
+
+import (
+	"context"
+	"fmt"
+)
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+type options struct {
+	id int
+}
+
+type server struct {
+	opts options
+}
+
+func worker(ctx context.Context, id int) {
+	defer HandlePanic()
+	fmt.Println(ctx, id)
+}
+
+func unguarded(id int) {
+	fmt.Println(id)
+}
+
+func (s *server) serve(id int) {
+	defer HandlePanic()
+	fmt.Println(id)
+}
+
+func goodTrampolines(ctx context.Context) {
+	s := &server{}
+	id := 1
+	go func() {
+		worker(ctx, id)
+	}()
+
+	opts := options{id: 2}
+	go func() {
+		worker(ctx, opts.id)
+	}()
+
+	go func() {
+		s.serve(3)
+	}()
+}
+
+func (s server) start(ctx context.Context) {
+	go func() {
+		worker(ctx, s.opts.id)
+	}()
+}
+
+func (s *server) startPointer(ctx context.Context) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: s may be nil"
+		worker(ctx, s.opts.id)
+	}()
+}
+
+func unguardedCallee() {
+	go func() { // want "missing defer call to HandlePanic: trampoline callee unguarded: first statement is not defer"
+		unguarded(1)
+	}()
+}
+
+func pointerArgument(ctx context.Context, opts *options) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: opts may be nil"
+		worker(ctx, opts.id)
+	}()
+}
+
+func indexArgument(ctx context.Context, ids []int) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: index ids\\[0\\] may be out of range"
+		worker(ctx, ids[0])
+	}()
+}
+
+func callArgument(ctx context.Context) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: call to nextID may panic"
+		worker(ctx, nextID())
+	}()
+}
+
+func nextID() int {
+	return 4
+}
+
+func nilReceiver(s *server) {
+	go func() { // want "missing defer call to HandlePanic: trampoline arguments: receiver s may be nil"
+		s.serve(5)
+	}()
+}
+
+func moreStatements(ctx context.Context) {
+	go func() { // want "missing defer call to HandlePanic: first statement is not defer"
+		fmt.Println("starting")
+		worker(ctx, 6)
+	}()
+}
+
+func guarded() {
+	defer HandlePanic()
+	fmt.Println("guarded")
+}
+
+type callbacks struct {
+	onEvent func()
+}
+
+func funcVariable() {
+	f := guarded
+	go func() {
+		f()
+	}()
+
+	g := func() {
+		defer HandlePanic()
+		fmt.Println("literal")
+	}
+	go func() {
+		g()
+	}()
+}
+
+func nilFuncVariable(ready bool) {
+	var f func()
+	if ready {
+		f = guarded
+	}
+	go func() { // want "missing defer call to HandlePanic: trampoline callee f may be nil"
+		f()
+	}()
+}
+
+func funcField(c callbacks) {
+	c.onEvent = guarded
+	go func() { // want "missing defer call to HandlePanic: trampoline callee c.onEvent may be nil"
+		c.onEvent()
+	}()
+}
+
+func callParam(f func()) {
+	go func() {
+		f()
+	}()
+}
+
+func callParamNil(f func()) {
+	go func() { // want "missing defer call to HandlePanic: trampoline callee f may be nil"
+		f()
+	}()
+}
+
+func params() {
+	callParam(guarded)
+	callParamNil(nil)
+}
@@ -0,0 +1,45 @@
+package wrapper
+
+import "fmt"
+
+func HandlePanic() {
+	if r := recover(); r != nil {
+		fmt.Println("recovered:", r)
+	}
+}
+
+func badWrapped() {
+	go func() {
+		defer func() { HandlePanic() }() // want "deferred closure calls HandlePanic\\(\\) instead of deferring it: recover\\(\\) only stops a panic when called directly by the deferred function"
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func badWrappedWithCleanup() {
+	go func() {
+		defer func() { // want "deferred closure calls HandlePanic\\(\\) instead of deferring it"
+			fmt.Println("cleanup")
+			HandlePanic()
+		}()
+		fmt.Println("Hello, World!")
+	}()
+}
+
+func wrappedWorker() {
+	defer func() { // want "deferred closure calls HandlePanic\\(\\) instead of deferring it"
+		HandlePanic()
+	}()
+	fmt.Println("Hello, World!")
+}
+
+func startWrappedWorker() {
+	go wrappedWorker()
+	go wrappedWorker()
+}
+
+func badOtherClosure() {
+	go func() { // want "missing defer call to HandlePanic: target mismatch"
+		defer func() { fmt.Println("cleanup") }()
+		fmt.Println("Hello, World!")
+	}()
+}
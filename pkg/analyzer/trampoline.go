@@ -0,0 +1,136 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// trampolineCall returns the call of a goroutine literal that does nothing
+// but call a named function or method, as in go func() { worker(ctx, id) }().
+func trampolineCall(pass *analysis.Pass, body *ast.BlockStmt) *ast.CallExpr {
+	if len(body.List) != 1 {
+		return nil
+	}
+	stmt, ok := body.List[0].(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	var name *ast.Ident
+	switch fun := unindex(call.Fun).(type) {
+	case *ast.Ident:
+		name = fun
+	case *ast.SelectorExpr:
+		name = fun.Sel
+	default:
+		return nil
+	}
+	switch obj := pass.TypesInfo.Uses[name].(type) {
+	case *types.Func:
+		// the standard library never defers the project's handler
+		if obj.Pkg() != nil && isStandardLibrary(pass, obj.Pkg()) {
+			return nil
+		}
+		return call
+	case *types.Var:
+		return call
+	}
+	return nil
+}
+
+// isStandardLibrary reports whether pkg is a package of the Go distribution:
+// one outside the analyzed package and module whose first path element, unlike
+// a module path's, has no dot.
+func isStandardLibrary(pass *analysis.Pass, pkg *types.Package) bool {
+	path := pkg.Path()
+	if pkg == pass.Pkg {
+		return false
+	}
+	if module := pass.Module; module != nil && module.Path != "" {
+		if path == module.Path || strings.HasPrefix(path, module.Path+"/") {
+			return false
+		}
+	}
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// nonNilFunc reports whether a func value is proven non-nil: a literal, a
+// named function, a variable only ever assigned such values, or a parameter
+// of an unexported function that is only called, with such values for it.
+func nonNilFunc(pass *analysis.Pass, checker *panicChecker, value ast.Expr, seen map[*types.Var]bool) bool {
+	if checker.nonNil(value) {
+		return true
+	}
+	ident, ok := ast.Unparen(value).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	param, ok := pass.TypesInfo.Uses[ident].(*types.Var)
+	if !ok || seen[param] {
+		return false
+	}
+	seen[param] = true
+	fn, index := paramOf(pass, param)
+	if fn == nil || fn.Exported() || !onlyCalled(pass, fn) {
+		return false
+	}
+	args := callArguments(pass, fn, index)
+	for _, arg := range args {
+		if !nonNilFunc(pass, checker, arg, seen) {
+			return false
+		}
+	}
+	return len(args) > 0
+}
+
+// onlyCalled reports whether every use of fn in the package calls it, so
+// callArguments sees every argument passed to it.
+func onlyCalled(pass *analysis.Pass, fn *types.Func) bool {
+	callees := map[*ast.Ident]bool{}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok && !call.Ellipsis.IsValid() {
+				if ident := calleeIdent(call.Fun); ident != nil {
+					callees[ident] = true
+				}
+			}
+			return true
+		})
+	}
+	for ident, obj := range pass.TypesInfo.Uses {
+		if used, ok := obj.(*types.Func); ok && used.Origin() == fn && !callees[ident] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkTrampoline accepts a trampoline when evaluating the callee and its
+// arguments cannot panic and the callee itself is guarded. Nothing else runs
+// before the callee's handler is deferred. Pointer receivers are not assumed
+// to be non-nil, so only fields of non-pointer values can be read. Calling a
+// nil func panics too, so a callee held in a variable or parameter must be
+// proven non-nil by nonNilFunc; one held in a field never is.
+func (p *Analyzer) checkTrampoline(pass *analysis.Pass, call *ast.CallExpr, callPos token.Pos) error {
+	checker := &panicChecker{info: pass.TypesInfo, files: pass.Files, nilReceivers: true}
+	if _, isVar := pass.TypesInfo.Uses[calleeIdent(call.Fun)].(*types.Var); isVar && !nonNilFunc(pass, checker, call.Fun, map[*types.Var]bool{}) {
+		return errors.Errorf("trampoline callee %s may be nil", types.ExprString(call.Fun))
+	}
+	if err := checker.operands(call); err != nil {
+		return errors.Wrap(err, "trampoline arguments")
+	}
+	if err := p.checkGoroutineDefinition(pass, call.Fun, callPos); err != nil {
+		return errors.Wrapf(err, "trampoline callee %s", types.ExprString(call.Fun))
+	}
+	return nil
+}
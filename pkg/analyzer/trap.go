@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// checkDeferredWrapper detects a deferred function literal that calls the
+// handler, as in defer func() { HandlePanic() }(). recover() only stops a
+// panic when it is called directly by the deferred function, so inside the
+// handler it returns nil and the panic continues. It returns nil when the
+// literal does not call a handler.
+func (p *Analyzer) checkDeferredWrapper(pass *analysis.Pass, deferStmt *ast.DeferStmt, typeInfo *types.Info) error {
+	lit, ok := deferStmt.Call.Fun.(*ast.FuncLit)
+	if !ok {
+		return nil
+	}
+
+	var handlerCall *ast.CallExpr
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit, *ast.DeferStmt:
+			return false
+		case *ast.CallExpr:
+			if handlerCall == nil && p.matchTargetCall(node.Fun, typeInfo) == nil {
+				handlerCall = node
+			}
+		}
+		return handlerCall == nil
+	})
+	if handlerCall == nil {
+		return nil
+	}
+
+	handler := types.ExprString(handlerCall)
+	diagnostic := analysis.Diagnostic{
+		Message: fmt.Sprintf("deferred closure calls %s instead of deferring it: recover() only stops a panic "+
+			"when called directly by the deferred function, so inside %s it returns nil; use defer %s",
+			handler, types.ExprString(handlerCall.Fun), handler),
+	}
+
+	if typeInfo == pass.TypesInfo {
+		diagnostic.Pos = deferStmt.Pos()
+		diagnostic.End = deferStmt.End()
+		if fix, ok := deferHandlerFix(pass, deferStmt, lit, handlerCall); ok {
+			diagnostic.SuggestedFixes = []analysis.SuggestedFix{fix}
+		}
+	}
+
+	return &guardError{diagnostic: diagnostic}
+}
+
+// deferHandlerFix rewrites the wrapper into a direct defer of the handler call.
+// It is only offered when the handler call is all the literal does and the
+// literal is called without arguments.
+func deferHandlerFix(pass *analysis.Pass, deferStmt *ast.DeferStmt, lit *ast.FuncLit, handlerCall *ast.CallExpr) (analysis.SuggestedFix, bool) {
+	if len(deferStmt.Call.Args) > 0 || len(lit.Body.List) != 1 {
+		return analysis.SuggestedFix{}, false
+	}
+	if stmt, ok := lit.Body.List[0].(*ast.ExprStmt); !ok || stmt.X != handlerCall {
+		return analysis.SuggestedFix{}, false
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, pass.Fset, handlerCall); err != nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	return analysis.SuggestedFix{
+		Message: fmt.Sprintf("Defer %s directly", types.ExprString(handlerCall.Fun)),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     deferStmt.Call.Pos(),
+			End:     deferStmt.Call.End(),
+			NewText: buf.Bytes(),
+		}},
+	}, true
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/golangci/plugin-module-register/register"
 	"golang.org/x/tools/go/analysis"
@@ -18,7 +19,97 @@ const (
 // Settings configures the golangci-lint plugin.
 type Settings struct {
 	// Target fully qualified handler identifier in the form full/pkg/path.Func.
+	// A comma-separated list accepts several handlers.
 	Target string `json:"target"`
+	// Targets lists additional accepted handlers, in the same form as Target.
+	Targets []string `json:"targets"`
+	// Reporters lists functions that deferred closures calling recover()
+	// themselves may pass the recovered value to instead of deferring a handler.
+	Reporters []string `json:"reporters"`
+	// LabelArg is the index of the handler argument carrying the goroutine
+	// label; when set, labels must be constant strings.
+	LabelArg *int `json:"label-arg"`
+	// LabelPattern is a regular expression goroutine labels must match.
+	LabelPattern string `json:"label-pattern"`
+	// LabelUnique is the scope labels must be unique in: package or module.
+	LabelUnique string `json:"label-unique"`
+	// Prologue lists functions whose calls and defers may come before the
+	// handler defer.
+	Prologue []string `json:"prologue"`
+	// Steps lists the calls every goroutine must make, in order, in its
+	// opening statements; "defer:" marks calls that must be deferred.
+	Steps []string `json:"steps"`
+	// DeferOrder reports defers registered before the handler: allowlist
+	// accepts the DeferAllow functions, strict none.
+	DeferOrder string `json:"defer-order"`
+	// DeferAllow lists functions that may be deferred before the handler.
+	DeferAllow []string `json:"defer-allow"`
+	// DeadGuards reports handler defers in functions never started as
+	// goroutines or called from guarded code.
+	DeadGuards bool `json:"dead-guards"`
+	// Bypass lists functions that terminate without a panic and must not be
+	// called from guarded goroutines.
+	Bypass []string `json:"bypass"`
+	// SSA replaces the first statement rule with a control flow check that
+	// the handler is deferred before any operation that can panic.
+	SSA bool `json:"ssa"`
+	// ExemptPanicFree accepts goroutines without a handler when their body
+	// provably cannot panic.
+	ExemptPanicFree bool `json:"exempt-panic-free"`
+	// NonDeferred reports handler calls that are not deferred, which recover
+	// nothing.
+	NonDeferred bool `json:"non-deferred"`
+	// VerifyHandlers reports configured handlers that never call recover()
+	// directly or discard the recovered value.
+	VerifyHandlers bool `json:"verify-handlers"`
+}
+
+// flag is an analyzer flag and the values a setting assigns to it.
+type flag struct {
+	name   string
+	values []string
+}
+
+// flags maps the settings onto the analyzer's command-line flags.
+func (s Settings) flags() []flag {
+	flags := []flag{
+		{"target", nonEmpty(s.Target)},
+		{"target", s.Targets},
+		{"reporter", s.Reporters},
+		{"label-pattern", nonEmpty(s.LabelPattern)},
+		{"label-unique", nonEmpty(s.LabelUnique)},
+		{"prologue", s.Prologue},
+		{"step", s.Steps},
+		{"defer-order", nonEmpty(s.DeferOrder)},
+		{"defer-allow", s.DeferAllow},
+		{"bypass", s.Bypass},
+	}
+	if s.SSA {
+		flags = append(flags, flag{"ssa", []string{"true"}})
+	}
+	if s.DeadGuards {
+		flags = append(flags, flag{"dead-guards", []string{"true"}})
+	}
+	if s.ExemptPanicFree {
+		flags = append(flags, flag{"exempt-panic-free", []string{"true"}})
+	}
+	if s.NonDeferred {
+		flags = append(flags, flag{"non-deferred", []string{"true"}})
+	}
+	if s.VerifyHandlers {
+		flags = append(flags, flag{"verify-handlers", []string{"true"}})
+	}
+	if s.LabelArg != nil {
+		flags = append(flags, flag{"label-arg", []string{strconv.Itoa(*s.LabelArg)}})
+	}
+	return flags
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
 }
 
 type Plugin struct {
@@ -47,9 +138,11 @@ func (p *Plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
 	logger := log.New(os.Stderr, "", log.LstdFlags|log.Lshortfile)
 	gdg := analyzer.New(logger)
 
-	if p.settings.Target != "" {
-		if err := gdg.Flags.Set("target", p.settings.Target); err != nil {
-			return nil, fmt.Errorf("set target flag: %w", err)
+	for _, f := range p.settings.flags() {
+		for _, value := range f.values {
+			if err := gdg.Flags.Set(f.name, value); err != nil {
+				return nil, fmt.Errorf("set %s flag: %w", f.name, err)
+			}
 		}
 	}
 
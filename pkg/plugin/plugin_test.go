@@ -38,3 +38,49 @@ func TestPluginRegistersAndConfiguresAnalyzer(t *testing.T) {
 		t.Fatalf("unexpected load mode: %s", got)
 	}
 }
+
+func TestPluginAcceptsTargetList(t *testing.T) {
+	newPlugin, err := register.GetPlugin(pluginName)
+	if err != nil {
+		t.Fatalf("expected plugin %q to be registered: %v", pluginName, err)
+	}
+
+	p, err := newPlugin(map[string]any{
+		"target":  "example.Target",
+		"targets": []any{"example/sentry.Recover", "example/testutil.CatchPanic"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error constructing plugin: %v", err)
+	}
+
+	analyzers, err := p.BuildAnalyzers()
+	if err != nil {
+		t.Fatalf("unexpected error building analyzers: %v", err)
+	}
+
+	want := "example.Target,example/sentry.Recover,example/testutil.CatchPanic"
+	if got := analyzers[0].Flags.Lookup("target").Value.String(); got != want {
+		t.Fatalf("target list not propagated to analyzer: got %s, want %s", got, want)
+	}
+}
+
+func TestPluginConfiguresReporters(t *testing.T) {
+	newPlugin, err := register.GetPlugin(pluginName)
+	if err != nil {
+		t.Fatalf("expected plugin %q to be registered: %v", pluginName, err)
+	}
+
+	p, err := newPlugin(map[string]any{"reporters": []any{"example/obs.Report"}})
+	if err != nil {
+		t.Fatalf("unexpected error constructing plugin: %v", err)
+	}
+
+	analyzers, err := p.BuildAnalyzers()
+	if err != nil {
+		t.Fatalf("unexpected error building analyzers: %v", err)
+	}
+
+	if got := analyzers[0].Flags.Lookup("reporter").Value.String(); got != "example/obs.Report" {
+		t.Fatalf("reporter not propagated to analyzer: %s", got)
+	}
+}
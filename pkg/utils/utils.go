@@ -1,7 +1,66 @@
 package utils
 
-import "strconv"
+import (
+	"sort"
+	"strconv"
+)
 
 func URI(path string, line int) string {
 	return path + ":" + strconv.Itoa(line)
 }
+
+// Closest returns up to limit candidates within a small edit distance of name,
+// nearest first.
+func Closest(name string, candidates []string, limit int) []string {
+	maxDistance := min(len(name)/3+1, 3)
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	seen := map[string]bool{}
+	for _, candidate := range candidates {
+		if candidate == name || seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		if d := editDistance(name, candidate); d <= maxDistance {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(matches) && i < limit; i++ {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}